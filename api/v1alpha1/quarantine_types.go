@@ -29,16 +29,44 @@ type QuarantineSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	Nodes     []Node     `json:"nodes,omitempty"`
-	Debug     Debug      `json:"debug,omitempty"`
-	Flags     Flags      `json:"flags,omitempty"`
-	Resources []Resource `json:"resources"`
+	Nodes   []Node  `json:"nodes,omitempty"`
+	Debug   Debug   `json:"debug,omitempty"`
+	Flags   Flags   `json:"flags,omitempty"`
+	Filters Filters `json:"filters,omitempty"`
+	// DefaultStrategy is applied to pods of workloads which are not listed under resources. If unset these pods are drained and pods left after the drain are evicted from isolated nodes
+	// +kubebuilder:validation:Enum=isolate;evict;delete;keep
+	DefaultStrategy string `json:"defaultStrategy,omitempty"`
+	// Mode selects how nodes are quarantined, auto drains ready nodes and handles other nodes as unreachable, soft keeps pods running
+	// +kubebuilder:validation:Enum=auto;drain;unreachable;soft
+	Mode         string         `json:"mode,omitempty"`
+	Soft         Soft           `json:"soft,omitempty"`
+	Safety       Safety         `json:"safety,omitempty"`
+	Drain        Drain          `json:"drain,omitempty"`
+	Verification Verification   `json:"verification,omitempty"`
+	Capacity     Capacity       `json:"capacity,omitempty"`
+	Impact       ImpactAnalysis `json:"impact,omitempty"`
+	Resources    []Resource     `json:"resources"`
+	Compare      Compare        `json:"compare,omitempty"`
+	Logs         Logs           `json:"logs,omitempty"`
+	Artifacts    *ArtifactSink  `json:"artifacts,omitempty"`
+	Evidence     Evidence       `json:"evidence,omitempty"`
+	Snapshots    Snapshots      `json:"snapshots,omitempty"`
+	// DryRun computes the actions of a quarantine and writes them to status without changing anything
+	DryRun        bool           `json:"dryRun,omitempty"`
+	Notifications []Notification `json:"notifications,omitempty"`
 }
 
 // Node defines a configuration for node to isolate
 type Node struct {
-	Name      string     `json:"name"`
-	Flags     Flags      `json:"flags,omitempty"`
+	Name    string  `json:"name"`
+	Flags   Flags   `json:"flags,omitempty"`
+	Filters Filters `json:"filters,omitempty"`
+	// DefaultStrategy overrides the default strategy of the quarantine for this node
+	// +kubebuilder:validation:Enum=isolate;evict;delete;keep
+	DefaultStrategy string `json:"defaultStrategy,omitempty"`
+	// Mode overrides the mode of the quarantine for this node
+	// +kubebuilder:validation:Enum=auto;drain;unreachable;soft
+	Mode      string     `json:"mode,omitempty"`
 	Isolate   bool       `json:"isolate,omitempty"`
 	Rescale   bool       `json:"rescale,omitempty"`
 	Resources []Resource `json:"resources,omitempty"`
@@ -53,6 +81,10 @@ type Resource struct {
 	Namespace string `json:"namespace,omitempty"`
 	// +kubebuilder:default:=false
 	Keep bool `json:"keep,omitempty"`
+	// Strategy defines how pods of the workload on a node are handled, isolate relabels them, evict evicts them respecting disruption budgets, delete force deletes them and keep adds a toleration and leaves them running
+	// +kubebuilder:validation:Enum=isolate;evict;delete;keep
+	// +kubebuilder:default:="isolate"
+	Strategy string `json:"strategy,omitempty"`
 }
 
 // Flag defines flags for draining a node
@@ -62,6 +94,88 @@ type Flags struct {
 	DeleteEmptyDirData  *bool `json:"deleteEmptyDirData,omitempty"`
 	Force               *bool `json:"force,omitempty"`
 	IgnoreErrors        *bool `json:"ignoreErrors,omitempty"`
+	// TimeoutSeconds limits the drain and the eviction of pods on an isolated node, 0 waits infinitely
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+	// GracePeriodSeconds is the time given to each pod to terminate, a negative value uses the grace period of the pod
+	GracePeriodSeconds *int `json:"gracePeriodSeconds,omitempty"`
+	// SkipWaitForDeleteTimeoutSeconds skips waiting for pods whose deletion timestamp is older than this, 0 never skips
+	// +kubebuilder:validation:Minimum=0
+	SkipWaitForDeleteTimeoutSeconds *int `json:"skipWaitForDeleteTimeoutSeconds,omitempty"`
+	// ChunkSize limits the number of pods listed per request on a node, 0 lists all at once
+	// +kubebuilder:validation:Minimum=0
+	ChunkSize *int64 `json:"chunkSize,omitempty"`
+	// WatchTimeoutSeconds limits waiting for a node to be updated after it was cordoned, tainted or drained
+	// +kubebuilder:validation:Minimum=1
+	WatchTimeoutSeconds *int64 `json:"watchTimeoutSeconds,omitempty"`
+}
+
+// Filters defines pods which are kept on a node by the drain and the eviction of an isolated node
+type Filters struct {
+	ExcludedNamespaces      []string               `json:"excludedNamespaces,omitempty"`
+	ExcludedPriorityClasses []string               `json:"excludedPriorityClasses,omitempty"`
+	ExcludedSelectors       []metav1.LabelSelector `json:"excludedSelectors,omitempty"`
+	// SkipLocalStorage keeps pods with emptyDir or hostPath volumes on the node
+	SkipLocalStorage *bool `json:"skipLocalStorage,omitempty"`
+}
+
+// Soft defines how new pods are kept away from nodes in soft mode
+type Soft struct {
+	// Cordon marks nodes unschedulable in addition to the PreferNoSchedule taint
+	Cordon bool `json:"cordon,omitempty"`
+}
+
+// Safety defines guards which prevent a quarantine from making too many nodes of the cluster unschedulable
+type Safety struct {
+	// MaxUnschedulablePercent is the share of nodes which may be unschedulable after quarantining, 50 if unset
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxUnschedulablePercent *int `json:"maxUnschedulablePercent,omitempty"`
+	// TopologyLabels group nodes into zones or pools in which at least one node has to stay schedulable, topology.kubernetes.io/zone if unset
+	TopologyLabels []string `json:"topologyLabels,omitempty"`
+	// AllowControlPlane allows quarantining control plane nodes
+	AllowControlPlane bool `json:"allowControlPlane,omitempty"`
+}
+
+// Drain defines the order in which pods are drained from a node
+type Drain struct {
+	// Waves are drained in sequence, pods which are not selected by any wave are drained afterwards
+	Waves []Wave `json:"waves,omitempty"`
+}
+
+// Wave defines pods which are drained together before the next wave starts
+type Wave struct {
+	Name     string               `json:"name"`
+	Selector metav1.LabelSelector `json:"selector"`
+	// PauseSeconds waits after the pods of the wave are drained, at most 5 minutes
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=300
+	PauseSeconds int64 `json:"pauseSeconds,omitempty"`
+	// WaitForReady waits until the workloads of the drained pods are ready again on other nodes
+	WaitForReady bool `json:"waitForReady,omitempty"`
+	// TimeoutSeconds limits waiting for ready workloads, the drain timeout or 5 minutes are used if not set
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// Verification defines the check that workloads of evicted pods are ready again on other nodes before a quarantine is active
+type Verification struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// TimeoutSeconds limits waiting for ready workloads, 120 seconds are used if not set
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// Capacity defines simulating whether pods of quarantined nodes fit on the remaining nodes before any node is touched
+type Capacity struct {
+	Enabled bool `json:"enabled"`
+	// Block stops the quarantine if pods would not fit on the remaining nodes
+	Block bool `json:"block,omitempty"`
+}
+
+// ImpactAnalysis defines computing which services, disruption budgets and ingresses are affected before any node is touched
+type ImpactAnalysis struct {
+	Enabled bool `json:"enabled"`
 }
 
 // Debug defines a debug pod configuration
@@ -74,9 +188,176 @@ type Debug struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// Compare defines a comparison of quarantined nodes against a healthy reference node
+type Compare struct {
+	// +kubebuilder:default:=false
+	Enabled bool `json:"enabled"`
+	// ReferenceNode is picked from nodes with the same pool label value if empty
+	ReferenceNode string `json:"referenceNode,omitempty"`
+	// +kubebuilder:default:="node.kubernetes.io/instance-type"
+	PoolLabel string `json:"poolLabel,omitempty"`
+	// TimeoutSeconds limits waiting for the debug pods on a node and its reference node, 30 seconds are used if not set
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// Logs defines streaming of isolated pod logs for the lifetime of a quarantine
+type Logs struct {
+	// +kubebuilder:default:=false
+	Enabled bool `json:"enabled"`
+	// +kubebuilder:default:=10
+	MaxSizeMB int `json:"maxSizeMB,omitempty"`
+	// +kubebuilder:default:=5
+	MaxFiles int `json:"maxFiles,omitempty"`
+}
+
+// ArtifactSink defines an S3 compatible object storage where artifacts of a quarantine are uploaded to
+type ArtifactSink struct {
+	Endpoint string `json:"endpoint"`
+	Bucket   string `json:"bucket"`
+	// +kubebuilder:default:="us-east-1"
+	Region string `json:"region,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	// CredentialsSecret is a secret in the namespace of the quarantine with keys accessKeyID and secretAccessKey
+	CredentialsSecret string `json:"credentialsSecret"`
+	// Insecure disables verification of the endpoint certificate
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// Evidence defines the integrity protection of collected artifacts
+type Evidence struct {
+	// SigningKeySecret is a secret in the namespace of the quarantine with a PEM encoded ed25519 key under signing.key
+	SigningKeySecret string `json:"signingKeySecret,omitempty"`
+}
+
+// Snapshots defines volume snapshots of persistent volume claims used by isolated pods
+type Snapshots struct {
+	// +kubebuilder:default:=false
+	Enabled                 bool   `json:"enabled"`
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+	// Retention defines when snapshots are deleted, they are kept until deleted manually if not set
+	Retention *metav1.Duration `json:"retention,omitempty"`
+	// +kubebuilder:default:=300
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
+// Notification defines a sink which is notified about lifecycle transitions of a quarantine
+type Notification struct {
+	// +kubebuilder:validation:Enum=webhook;slack;teams;cloudevents
+	Type string `json:"type"`
+	URL  string `json:"url,omitempty"`
+	// URLSecret is a secret in the namespace of the quarantine with the url under key url
+	URLSecret string `json:"urlSecret,omitempty"`
+	// Template is a go template rendering the payload, the default payload of the type is used if not set
+	Template string `json:"template,omitempty"`
+	// Events the sink is notified about, all events are sent if not set
+	Events []string `json:"events,omitempty"`
+}
+
 // QuarantineStatus defines the observed state of Quarantine
 type QuarantineStatus struct {
-	Conditions []metav1.Condition `json:"conditions"`
+	Conditions        []metav1.Condition `json:"conditions"`
+	Nodes             []string           `json:"nodes,omitempty"`
+	Comparisons       []NodeComparison   `json:"comparisons,omitempty"`
+	Artifacts         []string           `json:"artifacts,omitempty"`
+	VolumeSnapshots   []string           `json:"volumeSnapshots,omitempty"`
+	Plan              []PlannedAction    `json:"plan,omitempty"`
+	BlockedPods       []BlockedPod       `json:"blockedPods,omitempty"`
+	Waves             []WaveStatus       `json:"waves,omitempty"`
+	DegradedWorkloads []DegradedWorkload `json:"degradedWorkloads,omitempty"`
+	UnschedulablePods []UnschedulablePod `json:"unschedulablePods,omitempty"`
+	Impact            *Impact            `json:"impact,omitempty"`
+}
+
+// Impact represents the services, disruption budgets and ingresses affected by relabelling and draining pods of quarantined nodes
+type Impact struct {
+	Services          []ServiceImpact          `json:"services,omitempty"`
+	DisruptionBudgets []DisruptionBudgetImpact `json:"disruptionBudgets,omitempty"`
+	Ingresses         []IngressImpact          `json:"ingresses,omitempty"`
+}
+
+// ServiceImpact represents a service whose endpoint slices lose ready endpoints
+type ServiceImpact struct {
+	Namespace          string   `json:"namespace"`
+	Name               string   `json:"name"`
+	EndpointSlices     []string `json:"endpointSlices,omitempty"`
+	Endpoints          int32    `json:"endpoints"`
+	RemainingEndpoints int32    `json:"remainingEndpoints"`
+}
+
+// DisruptionBudgetImpact represents a disruption budget which has no disruptions allowed anymore
+type DisruptionBudgetImpact struct {
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	DisruptionsAllowed int32  `json:"disruptionsAllowed"`
+	Pods               int32  `json:"pods"`
+}
+
+// IngressImpact represents an ingress whose backend services have no ready endpoints left
+type IngressImpact struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Services  []string `json:"services"`
+}
+
+// UnschedulablePod represents a pod of a quarantined node which would not fit on any remaining node
+type UnschedulablePod struct {
+	Node   string `json:"node"`
+	Pod    string `json:"pod"`
+	Reason string `json:"reason"`
+}
+
+// DegradedWorkload represents a workload of evicted pods which has not all replicas ready again
+type DegradedWorkload struct {
+	Kind          string `json:"kind"`
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`
+	Replicas      int32  `json:"replicas"`
+	ReadyReplicas int32  `json:"readyReplicas"`
+}
+
+// WaveStatus represents the progress of a drain wave on a quarantined node
+type WaveStatus struct {
+	Node string `json:"node"`
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=Draining;Waiting;Completed;Failed
+	Phase   string `json:"phase"`
+	Pods    int    `json:"pods"`
+	Message string `json:"message,omitempty"`
+}
+
+// BlockedPod represents a pod which could not be evicted from a quarantined node
+type BlockedPod struct {
+	Node             string `json:"node"`
+	Pod              string `json:"pod"`
+	DisruptionBudget string `json:"disruptionBudget,omitempty"`
+	Reason           string `json:"reason"`
+}
+
+// PlannedAction represents a change a quarantine applies to the cluster
+type PlannedAction struct {
+	Node   string `json:"node"`
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// NodeComparison represents the differences between a quarantined node and its reference node
+type NodeComparison struct {
+	Node          string           `json:"node"`
+	ReferenceNode string           `json:"referenceNode"`
+	Differences   []NodeDifference `json:"differences,omitempty"`
+	// Truncated lists the categories with more differences than listed
+	Truncated []string `json:"truncated,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// NodeDifference represents a single collected value which differs between two nodes
+type NodeDifference struct {
+	Category  string `json:"category"`
+	Key       string `json:"key"`
+	Node      string `json:"node,omitempty"`
+	Reference string `json:"reference,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"log"
+
+	appcmd "github.com/soer3n/incident-operator/cmd"
+)
+
+func main() {
+	command := appcmd.NewPluginCmd()
+	if err := command.Execute(); err != nil {
+		log.Fatal(err.Error())
+	}
+}
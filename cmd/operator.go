@@ -19,6 +19,7 @@ package cmd
 
 import (
 	"flag"
+	"time"
 
 	"github.com/spf13/cobra"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -50,8 +51,18 @@ func newOperatorCmdServe() *cobra.Command {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var logDir string
+	var orphanMode string
+	var orphanNamespace string
+	var orphanInterval time.Duration
+	var notificationsConfig string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&logDir, "quarantine-log-dir", "/var/log/quarantine", "The directory where logs of isolated pods are written to.")
+	flag.StringVar(&orphanMode, "orphan-mode", "event", "How orphaned quarantine state is handled. One of event, adopt, cleanup or disabled.")
+	flag.StringVar(&orphanNamespace, "orphan-namespace", "default", "The namespace where quarantines for adopted orphaned nodes are created.")
+	flag.DurationVar(&orphanInterval, "orphan-interval", 10*time.Minute, "The interval in which orphaned quarantine state is looked for.")
+	flag.StringVar(&notificationsConfig, "notifications-config", "", "Path to a yaml file with a list of notification sinks which are notified about every quarantine.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -67,7 +78,7 @@ func newOperatorCmdServe() *cobra.Command {
 		Short: "runs the operator",
 		Long:  `apps operator`,
 		Run: func(cmd *cobra.Command, args []string) {
-			operator.Run(metricsAddr, probeAddr, enableLeaderElection)
+			operator.Run(metricsAddr, probeAddr, logDir, orphanMode, orphanNamespace, notificationsConfig, orphanInterval, enableLeaderElection)
 		},
 	}
 }
@@ -1,20 +1,29 @@
 package operator
 
 import (
+	"io/ioutil"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/yaml"
 
 	opsv1alpha1 "github.com/soer3n/incident-operator/api/v1alpha1"
 	"github.com/soer3n/incident-operator/controllers"
+	"github.com/soer3n/incident-operator/internal/quarantine"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -31,7 +40,13 @@ func init() {
 }
 
 // Run represents starting the quarantine operator
-func Run(metricsAddr, probeAddr string, enableLeaderElection bool) {
+func Run(metricsAddr, probeAddr, logDir, orphanMode, orphanNamespace, notificationsConfig string, orphanInterval time.Duration, enableLeaderElection bool) {
+
+	notifications, err := loadNotifications(notificationsConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to load notifications config")
+		os.Exit(1)
+	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
@@ -40,6 +55,11 @@ func Run(metricsAddr, probeAddr string, enableLeaderElection bool) {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "71b71418.soer3n.info",
+		NewCache: cache.BuilderWithOptions(cache.Options{
+			SelectorsByObject: cache.SelectorsByObject{
+				&corev1.Pod{}: {Label: controllers.MetricsPodSelector()},
+			},
+		}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -47,13 +67,44 @@ func Run(metricsAddr, probeAddr string, enableLeaderElection bool) {
 	}
 
 	if err = (&controllers.QuarantineReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("ops").WithName("Quarantine"),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("ops").WithName("Quarantine"),
+		Scheme:        mgr.GetScheme(),
+		LogStreamer:   quarantine.NewLogStreamer(logDir),
+		Recorder:      mgr.GetEventRecorderFor("quarantine-controller"),
+		Notifications: notifications,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Quarantine")
 		os.Exit(1)
 	}
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create clientset")
+		os.Exit(1)
+	}
+	dynamicClient, err := dynamic.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create dynamic client")
+		os.Exit(1)
+	}
+
+	if err = mgr.Add(&controllers.OrphanCollector{
+		Client:    mgr.GetClient(),
+		Clientset: clientset,
+		Dynamic:   dynamicClient,
+		Recorder:  mgr.GetEventRecorderFor("orphan-collector"),
+		Log:       ctrl.Log.WithName("controllers").WithName("ops").WithName("OrphanCollector"),
+		Mode:      orphanMode,
+		Namespace: orphanNamespace,
+		Interval:  orphanInterval,
+	}); err != nil {
+		setupLog.Error(err, "unable to create orphan collector")
+		os.Exit(1)
+	}
+	ctrlmetrics.Registry.MustRegister(&controllers.MetricsCollector{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ops").WithName("MetricsCollector"),
+	})
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -71,3 +122,21 @@ func Run(metricsAddr, probeAddr string, enableLeaderElection bool) {
 		os.Exit(1)
 	}
 }
+
+func loadNotifications(path string) ([]opsv1alpha1.Notification, error) {
+
+	notifications := []opsv1alpha1.Notification{}
+
+	if path == "" {
+		return notifications, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return notifications, err
+	}
+
+	err = yaml.Unmarshal(data, &notifications)
+	return notifications, err
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"github.com/soer3n/incident-operator/internal/cli"
+)
+
+// NewPluginCmd represents the root command of the kubectl quarantine plugin
+func NewPluginCmd() *cobra.Command {
+
+	flags := genericclioptions.NewConfigFlags(true)
+	streams := genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+
+	cmd := &cobra.Command{
+		Use:   "kubectl-quarantine",
+		Short: "manages quarantines of nodes",
+		Long:  `kubectl plugin for the lifecycle of quarantine resources`,
+	}
+
+	flags.AddFlags(cmd.PersistentFlags())
+	cmd.PersistentFlags().String("name", "", "name of the quarantine resource")
+
+	cmd.AddCommand(newPluginStartCmd(flags, streams))
+	cmd.AddCommand(newPluginStopCmd(flags, streams))
+	cmd.AddCommand(newPluginReleaseNodeCmd(flags, streams))
+	cmd.AddCommand(newPluginStatusCmd(flags, streams))
+	cmd.AddCommand(newPluginDebugCmd(flags, streams))
+
+	return cmd
+}
+
+func newPluginStartCmd(flags *genericclioptions.ConfigFlags, streams genericclioptions.IOStreams) *cobra.Command {
+
+	opts := cli.StartOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "start <node>",
+		Short: "starts a quarantine for a node",
+		Long:  `creates a quarantine resource from flags`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			p := newPlugin(flags, streams)
+			opts.Name, _ = cmd.Flags().GetString("name")
+
+			if err := p.Start(args[0], opts); err != nil {
+				log.Fatal(err.Error())
+			}
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&opts.Isolate, "isolate", []string{}, "workload whose pods are kept on the node as [namespace/]deploy/name or [namespace/]ds/name")
+	cmd.Flags().BoolVar(&opts.Evict, "evict-daemonsets", false, "evict pods of daemonsets which are not isolated")
+	cmd.Flags().BoolVar(&opts.Debug, "debug", false, "deploy a debug pod on the node")
+	cmd.Flags().StringVar(&opts.DebugImage, "debug-image", "", "image of the debug pod")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "only compute the plan of the quarantine")
+
+	return cmd
+}
+
+func newPluginStopCmd(flags *genericclioptions.ConfigFlags, streams genericclioptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "stops a quarantine",
+		Long:  `deletes a quarantine resource which reverts all of its nodes`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			p := newPlugin(flags, streams)
+			name, _ := cmd.Flags().GetString("name")
+
+			if err := p.Stop(name); err != nil {
+				log.Fatal(err.Error())
+			}
+		},
+	}
+}
+
+func newPluginReleaseNodeCmd(flags *genericclioptions.ConfigFlags, streams genericclioptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "release-node <node>",
+		Short: "releases a single node from a quarantine",
+		Long:  `removes a node from the spec of a quarantine so that the operator releases it`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			p := newPlugin(flags, streams)
+			name, _ := cmd.Flags().GetString("name")
+
+			if err := p.ReleaseNode(name, args[0]); err != nil {
+				log.Fatal(err.Error())
+			}
+		},
+	}
+}
+
+func newPluginStatusCmd(flags *genericclioptions.ConfigFlags, streams genericclioptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "shows the state of quarantines",
+		Long:  `lists nodes, isolated pods and debug pods of quarantines`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			p := newPlugin(flags, streams)
+			name, _ := cmd.Flags().GetString("name")
+
+			if err := p.Status(name); err != nil {
+				log.Fatal(err.Error())
+			}
+		},
+	}
+}
+
+func newPluginDebugCmd(flags *genericclioptions.ConfigFlags, streams genericclioptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "debug <node> [-- command]",
+		Short: "opens a shell in the debug pod of a node",
+		Long:  `execs into the debug pod of a quarantined node`,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			p := newPlugin(flags, streams)
+			name, _ := cmd.Flags().GetString("name")
+
+			if err := p.Debug(name, args[0], args[1:]); err != nil {
+				log.Fatal(err.Error())
+			}
+		},
+	}
+}
+
+func newPlugin(flags *genericclioptions.ConfigFlags, streams genericclioptions.IOStreams) *cli.Plugin {
+
+	p, err := cli.NewPlugin(flags, streams)
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	return p
+}
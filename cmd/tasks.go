@@ -17,6 +17,10 @@ func NewTasksCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(NewJobRescheduleCmd())
+	cmd.AddCommand(NewVerifyEvidenceCmd())
+	cmd.AddCommand(NewPlanCmd())
+	cmd.AddCommand(NewReleaseCmd())
+	cmd.AddCommand(NewAuditExportCmd())
 
 	return cmd
 }
@@ -44,3 +48,160 @@ func NewJobRescheduleCmd() *cobra.Command {
 
 	return cmd
 }
+
+// NewVerifyEvidenceCmd represents the verify-evidence subcommand
+func NewVerifyEvidenceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-evidence",
+		Short: "verifies a downloaded evidence bundle of a quarantine",
+		Long:  `offline verification of signature, hash chain and artifacts of an evidence bundle`,
+		Run: func(cmd *cobra.Command, args []string) {
+			bundle, err := cmd.Flags().GetString("bundle")
+
+			if err != nil {
+				return
+			}
+
+			publicKey, err := cmd.Flags().GetString("public-key")
+
+			if err != nil {
+				return
+			}
+
+			if err = cli.VerifyEvidence(bundle, publicKey); err != nil {
+				log.Fatal(err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().String("bundle", ".", "directory with manifest.json, manifest.sig and the artifacts of a quarantine")
+	cmd.PersistentFlags().String("public-key", "", "path to the PEM encoded ed25519 public key")
+
+	return cmd
+}
+
+// NewPlanCmd represents the plan subcommand
+func NewPlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "previews the actions of a quarantine without applying them",
+		Long:  `offline quarantine preview against the live cluster`,
+		Run: func(cmd *cobra.Command, args []string) {
+			file, err := cmd.Flags().GetString("file")
+
+			if err != nil {
+				return
+			}
+
+			output, err := cmd.Flags().GetString("output")
+
+			if err != nil {
+				return
+			}
+
+			if err = cli.PlanQuarantine(file, output); err != nil {
+				log.Fatal(err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringP("file", "f", "", "path to the quarantine manifest")
+	cmd.PersistentFlags().StringP("output", "o", "table", "output format of the plan (table, json or markdown)")
+
+	return cmd
+}
+
+// NewReleaseCmd represents the release subcommand
+func NewReleaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "reverts quarantine artifacts without a running operator",
+		Long:  `break-glass removal of taints, cordons, labels, isolated and debug pods and tolerations`,
+		Run: func(cmd *cobra.Command, args []string) {
+			nodes, err := cmd.Flags().GetStringSlice("node")
+
+			if err != nil {
+				return
+			}
+
+			all, err := cmd.Flags().GetBool("all")
+
+			if err != nil {
+				return
+			}
+
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+
+			if err != nil {
+				return
+			}
+
+			auditNamespace, err := cmd.Flags().GetString("audit-namespace")
+
+			if err != nil {
+				return
+			}
+
+			if len(nodes) == 0 && !all {
+				log.Fatal("either --node or --all is required")
+			}
+
+			if len(nodes) > 0 && all {
+				log.Fatal("--node and --all are mutually exclusive")
+			}
+
+			if err = cli.ReleaseNodes(nodes, dryRun, auditNamespace); err != nil {
+				log.Fatal(err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringSlice("node", []string{}, "nodes to release")
+	cmd.PersistentFlags().Bool("all", false, "release every node with quarantine artifacts")
+	cmd.PersistentFlags().Bool("dry-run", false, "only report the actions without changing anything")
+	cmd.PersistentFlags().String("audit-namespace", "default", "namespace the audit records of the release are written to")
+
+	return cmd
+}
+
+// NewAuditExportCmd represents the audit-export subcommand
+func NewAuditExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit-export",
+		Short: "exports the audit trail of a quarantine",
+		Long:  `prints every recorded cluster mutation of a quarantine with its diff, actor and timestamp`,
+		Run: func(cmd *cobra.Command, args []string) {
+			namespace, err := cmd.Flags().GetString("namespace")
+
+			if err != nil {
+				return
+			}
+
+			name, err := cmd.Flags().GetString("name")
+
+			if err != nil {
+				return
+			}
+
+			output, err := cmd.Flags().GetString("output")
+
+			if err != nil {
+				return
+			}
+
+			if name == "" {
+				log.Fatal("--name is required")
+			}
+
+			if err = cli.ExportAudit(namespace, name, output); err != nil {
+				log.Fatal(err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringP("namespace", "n", "default", "namespace of the quarantine")
+	cmd.PersistentFlags().String("name", "", "name of the quarantine, orphan-collector or break-glass")
+	cmd.PersistentFlags().StringP("output", "o", "jsonl", "output format of the records (jsonl, json or table)")
+
+	return cmd
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+)
+
+const (
+	phasePending  = "pending"
+	phaseDryRun   = "dryrun"
+	phaseActive   = "active"
+	phaseFailed   = "failed"
+	phaseStopping = "stopping"
+)
+
+const debugPodPrefix = "quarantine-debug-"
+
+const metricsTimeout = 10 * time.Second
+
+var (
+	quarantinedNodesDesc = prometheus.NewDesc("quarantine_nodes", "Nodes tainted by a quarantine.", nil, nil)
+	isolatedPodsDesc     = prometheus.NewDesc("quarantine_isolated_pods", "Pods isolated from their workload by a quarantine.", []string{"namespace", "workload"}, nil)
+	debugPodsDesc        = prometheus.NewDesc("quarantine_debug_pods", "Debug pods deployed on quarantined nodes.", nil, nil)
+	quarantinesDesc      = prometheus.NewDesc("quarantine_resources", "Quarantine resources per phase.", []string{"phase"}, nil)
+)
+
+//+kubebuilder:rbac:groups="",resources=nodes;pods,verbs=list;watch
+
+// MetricsCollector exposes the current state of quarantines in the cluster on every scrape.
+// Client should read from the cache of the manager so that scrapes do not hit the api server.
+type MetricsCollector struct {
+	Client client.Client
+	Log    logr.Logger
+}
+
+// MetricsPodSelector returns the label selector of pods the collector reads. The cache of the manager only needs to hold these pods.
+func MetricsPodSelector() labels.Selector {
+	return labels.SelectorFromSet(labels.Set{quarantine.QuarantinePodLabelPrefix + quarantine.QuarantinePodLabelKey: "true"})
+}
+
+// Describe sends the descriptors of all metrics of the collector
+func (m *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- quarantinedNodesDesc
+	ch <- isolatedPodsDesc
+	ch <- debugPodsDesc
+	ch <- quarantinesDesc
+}
+
+// Collect sends the current values of all metrics of the collector
+func (m *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), metricsTimeout)
+	defer cancel()
+
+	quarantines := &v1alpha1.QuarantineList{}
+
+	if err := m.Client.List(ctx, quarantines); err != nil {
+		m.Log.Error(err, "list quarantines for metrics")
+	} else {
+		phases := map[string]int{phasePending: 0, phaseDryRun: 0, phaseActive: 0, phaseFailed: 0, phaseStopping: 0}
+
+		for _, q := range quarantines.Items {
+			phases[quarantinePhase(q)]++
+		}
+
+		for phase, count := range phases {
+			ch <- prometheus.MustNewConstMetric(quarantinesDesc, prometheus.GaugeValue, float64(count), phase)
+		}
+	}
+
+	nodes := &corev1.NodeList{}
+
+	if err := m.Client.List(ctx, nodes); err != nil {
+		m.Log.Error(err, "list nodes for metrics")
+	} else {
+		count := 0
+
+		for _, node := range nodes.Items {
+			if quarantine.HasQuarantineTaint(node) {
+				count++
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(quarantinedNodesDesc, prometheus.GaugeValue, float64(count))
+	}
+
+	pods := &corev1.PodList{}
+
+	if err := m.Client.List(ctx, pods, client.MatchingLabelsSelector{Selector: MetricsPodSelector()}); err != nil {
+		m.Log.Error(err, "list pods for metrics")
+		return
+	}
+
+	debugPods := 0
+	isolatedPods := map[[2]string]int{}
+
+	for _, pod := range pods.Items {
+
+		if strings.HasPrefix(pod.ObjectMeta.Name, debugPodPrefix) {
+			debugPods++
+			continue
+		}
+
+		workload := pod.ObjectMeta.Annotations[quarantine.QuarantinePodLabelPrefix+quarantine.QuarantinePodWorkloadAnnotation]
+
+		if workload == "" {
+			workload = "unknown"
+		}
+
+		isolatedPods[[2]string{pod.ObjectMeta.Namespace, workload}]++
+	}
+
+	ch <- prometheus.MustNewConstMetric(debugPodsDesc, prometheus.GaugeValue, float64(debugPods))
+
+	for k, count := range isolatedPods {
+		ch <- prometheus.MustNewConstMetric(isolatedPodsDesc, prometheus.GaugeValue, float64(count), k[0], k[1])
+	}
+}
+
+func quarantinePhase(q v1alpha1.Quarantine) string {
+
+	if q.GetDeletionTimestamp() != nil {
+		return phaseStopping
+	}
+
+	if q.Spec.DryRun {
+		return phaseDryRun
+	}
+
+	if len(q.Status.Conditions) == 0 {
+		return phasePending
+	}
+
+	if meta.IsStatusConditionTrue(q.Status.Conditions, quarantineStatusKey) {
+		return phaseActive
+	}
+
+	return phaseFailed
+}
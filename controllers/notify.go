@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/notifier"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+const notificationURLSecretKey = "url"
+
+// notify sends a lifecycle transition of a quarantine to the cluster wide and the quarantine specific sinks
+func (r *QuarantineReconciler) notify(c kubernetes.Interface, instance *v1alpha1.Quarantine, e notifier.Event, reqLogger logr.Logger) {
+
+	n := &notifier.Notifier{}
+	notifications := append([]v1alpha1.Notification{}, r.Notifications...)
+	notifications = append(notifications, instance.Spec.Notifications...)
+
+	for _, config := range notifications {
+
+		url := config.URL
+
+		if config.URLSecret != "" {
+			secret, err := c.CoreV1().Secrets(instance.ObjectMeta.Namespace).Get(context.TODO(), config.URLSecret, metav1.GetOptions{})
+
+			if err != nil {
+				reqLogger.Error(err, "get notification url", "secret", config.URLSecret)
+				continue
+			}
+
+			url = string(secret.Data[notificationURLSecretKey])
+		}
+
+		sink, err := notifier.NewSink(config.Type, url, config.Template, config.Events)
+
+		if err != nil {
+			reqLogger.Error(err, "notification sink")
+			continue
+		}
+
+		n.Sinks = append(n.Sinks, sink)
+	}
+
+	if len(n.Sinks) == 0 {
+		return
+	}
+
+	e.Namespace = instance.ObjectMeta.Namespace
+	e.Name = instance.ObjectMeta.Name
+
+	if err := n.Notify(context.TODO(), e); err != nil {
+		reqLogger.Error(err, "notify", "event", e.Type)
+	}
+}
+
+// notifyNodeChanges sends an event for every node which was added to or removed from an active quarantine
+func (r *QuarantineReconciler) notifyNodeChanges(c kubernetes.Interface, instance *v1alpha1.Quarantine, previous, current []string, reqLogger logr.Logger) {
+
+	// nodes of a quarantine which was not started yet are part of the started event
+	if len(previous) == 0 {
+		return
+	}
+
+	for _, node := range current {
+		if !utils.Contains(previous, node) {
+			r.notify(c, instance, notifier.Event{Type: notifier.EventNodeAdded, Node: node, Nodes: current}, reqLogger)
+		}
+	}
+
+	for _, node := range previous {
+		if !utils.Contains(current, node) {
+			r.notify(c, instance, notifier.Event{Type: notifier.EventNodeRemoved, Node: node, Nodes: current}, reqLogger)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/audit"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+const (
+	// OrphanModeEvent only emits warning events for orphaned quarantine state
+	OrphanModeEvent = "event"
+	// OrphanModeAdopt creates a quarantine resource for orphaned nodes
+	OrphanModeAdopt = "adopt"
+	// OrphanModeCleanup reverts orphaned quarantine state
+	OrphanModeCleanup = "cleanup"
+	// OrphanModeDisabled disables the collector
+	OrphanModeDisabled = "disabled"
+)
+
+const orphanEventReason = "OrphanedQuarantine"
+
+// orphanAuditName is the name the collector records its mutations under in the orphan namespace
+const orphanAuditName = "orphan-collector"
+
+// OrphanCollector periodically looks for quarantine state which is not accounted for by any quarantine resource
+// and deletes volume snapshots of quarantines whose retention expired
+type OrphanCollector struct {
+	Client    client.Client
+	Clientset kubernetes.Interface
+	Dynamic   dynamic.Interface
+	Recorder  record.EventRecorder
+	Log       logr.Logger
+	Mode      string
+	Namespace string
+	Interval  time.Duration
+	// candidates found in the previous run, state is only handled if it is orphaned in two consecutive runs
+	candidates map[string]bool
+}
+
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Start runs the collector until the context is cancelled. Expired snapshots are pruned even if the collector is disabled.
+func (o *OrphanCollector) Start(ctx context.Context) error {
+
+	switch o.Mode {
+	case OrphanModeEvent, OrphanModeAdopt, OrphanModeCleanup, OrphanModeDisabled:
+	default:
+		return errors.New("unknown orphan collector mode " + o.Mode)
+	}
+
+	o.Log.Info("starting orphan collector...", "mode", o.Mode, "interval", o.Interval.String())
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if o.Mode != OrphanModeDisabled {
+			if err := o.Collect(ctx); err != nil {
+				o.Log.Error(err, "collect orphaned quarantine state")
+			}
+		}
+
+		if err := o.PruneSnapshots(); err != nil {
+			o.Log.Error(err, "prune snapshots")
+		}
+	}, o.Interval)
+
+	return nil
+}
+
+// NeedLeaderElection makes sure that only the leading manager handles orphans
+func (o *OrphanCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Collect represents a single run looking for and handling orphaned quarantine state
+func (o *OrphanCollector) Collect(ctx context.Context) error {
+
+	quarantines := &v1alpha1.QuarantineList{}
+
+	if err := o.Client.List(ctx, quarantines); err != nil {
+		return err
+	}
+
+	accounted := []string{}
+
+	for _, q := range quarantines.Items {
+		for _, n := range q.Spec.Nodes {
+			accounted = append(accounted, n.Name)
+		}
+	}
+
+	nodes, err := o.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: quarantine.QuarantinePodLabelPrefix + quarantine.QuarantinePodLabelKey + "=true",
+	}
+
+	pods, err := o.Clientset.CoreV1().Pods("").List(ctx, listOpts)
+
+	if err != nil {
+		return err
+	}
+
+	candidates := map[string]bool{}
+	orphanedNodes := []corev1.Node{}
+	orphanedPods := []corev1.Pod{}
+
+	for _, node := range nodes.Items {
+
+		if !quarantine.HasQuarantineTaint(node) || utils.Contains(accounted, node.ObjectMeta.Name) {
+			continue
+		}
+
+		key := "node/" + node.ObjectMeta.Name
+		candidates[key] = true
+
+		if o.candidates[key] {
+			orphanedNodes = append(orphanedNodes, node)
+		}
+	}
+
+	for _, pod := range pods.Items {
+
+		if utils.Contains(accounted, pod.Spec.NodeName) {
+			continue
+		}
+
+		key := "pod/" + pod.ObjectMeta.Namespace + "/" + pod.ObjectMeta.Name
+		candidates[key] = true
+
+		if o.candidates[key] {
+			orphanedPods = append(orphanedPods, pod)
+		}
+	}
+
+	o.candidates = candidates
+
+	if len(orphanedNodes) == 0 && len(orphanedPods) == 0 {
+		return nil
+	}
+
+	o.Log.Info("orphaned quarantine state found", "nodes", len(orphanedNodes), "pods", len(orphanedPods))
+
+	switch o.Mode {
+	case OrphanModeAdopt:
+		return o.adopt(ctx, orphanedNodes, orphanedPods)
+	case OrphanModeCleanup:
+		return o.cleanup(ctx, orphanedNodes, orphanedPods)
+	}
+
+	o.record(orphanedNodes, orphanedPods)
+	return nil
+}
+
+func (o *OrphanCollector) record(nodes []corev1.Node, pods []corev1.Pod) {
+
+	for i := range nodes {
+		o.Recorder.Event(&nodes[i], corev1.EventTypeWarning, orphanEventReason, "node is tainted by a quarantine which does not exist anymore")
+	}
+
+	for i := range pods {
+		o.Recorder.Event(&pods[i], corev1.EventTypeWarning, orphanEventReason, "pod is labeled by a quarantine which does not exist anymore")
+	}
+}
+
+// adopt creates a quarantine for each orphaned node so that it can be released by deleting the resource.
+// The created quarantine is annotated as adopted, so the node is taken over as it is and pods on it are not evicted.
+func (o *OrphanCollector) adopt(ctx context.Context, nodes []corev1.Node, pods []corev1.Pod) error {
+
+	adopted := []string{}
+
+	for i, node := range nodes {
+
+		q := &v1alpha1.Quarantine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "orphaned-" + node.ObjectMeta.Name,
+				Namespace: o.Namespace,
+				Annotations: map[string]string{
+					quarantine.QuarantinePodLabelPrefix + quarantine.QuarantineAdoptedAnnotation: time.Now().UTC().Format(time.RFC3339),
+				},
+			},
+			Spec: v1alpha1.QuarantineSpec{
+				Nodes: []v1alpha1.Node{
+					{Name: node.ObjectMeta.Name},
+				},
+				// resources are required by the schema
+				Resources: []v1alpha1.Resource{},
+			},
+		}
+
+		if err := o.Client.Create(ctx, q); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+
+		adopted = append(adopted, node.ObjectMeta.Name)
+		o.Recorder.Event(&nodes[i], corev1.EventTypeNormal, orphanEventReason, "adopted by quarantine "+o.Namespace+"/"+q.ObjectMeta.Name)
+		o.Log.Info("orphaned node adopted", "node", node.ObjectMeta.Name, "quarantine", q.ObjectMeta.Name)
+	}
+
+	// pods on nodes which are not quarantined anymore would quarantine a healthy node if adopted
+	remaining := []corev1.Pod{}
+
+	for _, pod := range pods {
+		if !utils.Contains(adopted, pod.Spec.NodeName) {
+			remaining = append(remaining, pod)
+		}
+	}
+
+	o.record([]corev1.Node{}, remaining)
+	return nil
+}
+
+// PruneSnapshots represents deleting volume snapshots whose retention expired. Snapshots outlive their quarantine
+// so they are pruned here instead of by the quarantine.
+func (o *OrphanCollector) PruneSnapshots() error {
+
+	if o.Dynamic == nil {
+		return nil
+	}
+
+	trail := audit.NewTrail(o.Namespace + "/" + orphanAuditName)
+
+	defer func() {
+		if err := trail.Flush(o.Clientset, o.Namespace, orphanAuditName); err != nil {
+			o.Log.Error(err, "write audit records")
+		}
+	}()
+
+	err := quarantine.PruneSnapshots(o.Dynamic, trail, o.Log)
+
+	// the snapshot api is optional
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (o *OrphanCollector) cleanup(ctx context.Context, nodes []corev1.Node, pods []corev1.Pod) error {
+
+	names := []string{}
+	trail := audit.NewTrail(o.Namespace + "/" + orphanAuditName)
+
+	defer func() {
+		if err := trail.Flush(o.Clientset, o.Namespace, orphanAuditName); err != nil {
+			o.Log.Error(err, "write audit records")
+		}
+	}()
+
+	for _, node := range nodes {
+		names = append(names, node.ObjectMeta.Name)
+	}
+
+	if len(names) > 0 {
+		plan, err := quarantine.Release(o.Clientset, names, false, trail)
+
+		for _, a := range plan.Actions {
+			o.Log.Info("orphaned state reverted", "node", a.Node, "kind", a.Kind, "target", a.Target)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, pod := range pods {
+
+		// pods on released nodes are already deleted
+		if utils.Contains(names, pod.Spec.NodeName) {
+			continue
+		}
+
+		if err := o.Clientset.CoreV1().Pods(pod.ObjectMeta.Namespace).Delete(ctx, pod.ObjectMeta.Name, metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+
+		trail.Add(audit.VerbDelete, "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, &pod, nil)
+		o.Log.Info("orphaned pod deleted", "pod", pod.ObjectMeta.Namespace+"/"+pod.ObjectMeta.Name)
+	}
+
+	return nil
+}
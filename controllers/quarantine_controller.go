@@ -19,8 +19,13 @@ package controllers
 
 import (
 	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -31,27 +36,50 @@ import (
 	"github.com/go-logr/logr"
 
 	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/metrics"
+	"github.com/soer3n/incident-operator/internal/notifier"
 	"github.com/soer3n/incident-operator/internal/quarantine"
 	"github.com/soer3n/incident-operator/internal/utils"
 	meta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/kubectl/pkg/cmd/util"
 )
 
 const quarantineFinalizer = "finalizer.quarantine.ops.soer3n.info"
 const quarantineStatusKey = "active"
+const quarantineDegradedKey = "Degraded"
+
+// logStreamRequeue is the interval in which active quarantines look for isolated pods whose logs are not followed yet
+const logStreamRequeue = 30 * time.Second
 
 // QuarantineReconciler reconciles a Quarantine object
 type QuarantineReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Log    logr.Logger
+	Scheme      *runtime.Scheme
+	Log         logr.Logger
+	LogStreamer *quarantine.LogStreamer
+	Recorder    record.EventRecorder
+	// Notifications are cluster wide sinks which are notified about every quarantine
+	Notifications []v1alpha1.Notification
 }
 
 //+kubebuilder:rbac:groups=ops.soer3n.info,resources=quarantines,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ops.soer3n.info,resources=quarantines/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ops.soer3n.info,resources=quarantines/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+//+kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+//+kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims;persistentvolumes,verbs=get
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;update
+//+kubebuilder:rbac:groups=apps,resources=deployments;replicasets;statefulsets,verbs=get
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=volumeattachments,verbs=list
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=list
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=list
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;create;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -99,9 +127,13 @@ func (r *QuarantineReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
+	q.Streamer = r.LogStreamer
+	q.Events.Recorder = r.Recorder
+	q.Progress.Report = r.reportWaves(instance, reqLogger)
+
 	if requeue, err = r.handleFinalizer(instance, q, reqLogger); err != nil {
 		reqLogger.Error(err, "error on handling resource finalizer")
-		return r.syncStatus(context.Background(), instance, reqLogger, metav1.ConditionFalse, "finalizer", err.Error())
+		return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, "finalizer", err.Error())
 	}
 
 	if requeue {
@@ -114,32 +146,92 @@ func (r *QuarantineReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
+	if q.DryRun {
+
+		// the plan is only computed again if the spec changed
+		if c := meta.FindStatusCondition(instance.Status.Conditions, quarantineStatusKey); c != nil && c.Reason == quarantine.ReasonDryRun && c.ObservedGeneration == instance.Generation {
+			reqLogger.Info("plan is up to date.")
+			return ctrl.Result{}, nil
+		}
+
+		reqLogger.Info("computing plan...")
+
+		if err := q.CheckSafety(); err != nil {
+			reqLogger.Error(err, "quarantine violates cluster safety guards")
+			return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, failureReason(err, "safety"), err.Error())
+		}
+
+		if err := q.Prepare(); err != nil {
+			reqLogger.Error(err, "error in planning")
+			return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, failureReason(err, "prepare"), err.Error())
+		}
+
+		if err := q.Start(); err != nil {
+			reqLogger.Error(err, "error in planning")
+			return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, "starting", err.Error())
+		}
+
+		return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, quarantine.ReasonDryRun, "planned")
+	}
+
 	if q.IsActive() {
 		reqLogger.Info("Quarantine already active. Update if needed.")
 
 		if err := q.Update(); err != nil {
 			reqLogger.Error(err, "error in reconciling")
-			return r.syncStatus(context.Background(), instance, reqLogger, metav1.ConditionFalse, "update", err.Error())
+			return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, failureReason(err, "update"), err.Error())
+		}
+
+		if q.IsDegraded() {
+			return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, "verifying", degradedMessage(q))
+		}
+
+		// the quarantine is started once the degraded workloads recovered
+		if len(instance.Status.DegradedWorkloads) > 0 {
+			r.notify(q.Client, instance, notifier.Event{Type: notifier.EventStarted, Nodes: q.NodeNames()}, reqLogger)
+			return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionTrue, "running", "success")
+		}
+
+		// persist added or removed nodes
+		if !equality.Semantic.DeepEqual(instance.Status.Nodes, q.NodeNames()) {
+			return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionTrue, "running", "success")
+		}
+
+		// pods isolated later and streams which ended are only picked up by a reconcile
+		if q.Logs.Enabled {
+			return ctrl.Result{RequeueAfter: logStreamRequeue}, nil
 		}
 
 		return ctrl.Result{}, nil
 	}
 
+	// the quarantine is paused until it does not violate the safety guards anymore
+	if err := q.CheckSafety(); err != nil {
+		reqLogger.Error(err, "quarantine violates cluster safety guards")
+		return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, failureReason(err, "safety"), err.Error())
+	}
+
 	reqLogger.Info("preparing...")
 
 	if err := q.Prepare(); err != nil {
 		reqLogger.Error(err, "error in reconciling")
-		return r.syncStatus(context.Background(), instance, reqLogger, metav1.ConditionFalse, "prepare", err.Error())
+		return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, failureReason(err, "prepare"), err.Error())
 	}
 
 	reqLogger.Info("starting...")
 
 	if err := q.Start(); err != nil {
 		reqLogger.Error(err, "error in reconciling")
-		return r.syncStatus(context.Background(), instance, reqLogger, metav1.ConditionFalse, "starting", err.Error())
+		return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, "starting", err.Error())
 	}
 
-	return r.syncStatus(context.Background(), instance, reqLogger, metav1.ConditionTrue, "running", "success")
+	if q.IsDegraded() {
+		return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionFalse, "verifying", degradedMessage(q))
+	}
+
+	r.notify(q.Client, instance, notifier.Event{Type: notifier.EventStarted, Nodes: q.NodeNames()}, reqLogger)
+
+	return r.syncStatus(context.Background(), instance, q, reqLogger, metav1.ConditionTrue, "running", "success")
 }
 
 func (r *QuarantineReconciler) handleFinalizer(instance *v1alpha1.Quarantine, obj *quarantine.Quarantine, reqLogger logr.Logger) (bool, error) {
@@ -150,6 +242,11 @@ func (r *QuarantineReconciler) handleFinalizer(instance *v1alpha1.Quarantine, ob
 			return true, err
 		}
 
+		if !obj.DryRun {
+			metrics.QuarantineDuration.Observe(time.Since(instance.GetCreationTimestamp().Time).Seconds())
+			r.notify(obj.Client, instance, notifier.Event{Type: notifier.EventReleased, Nodes: obj.NodeNames()}, reqLogger)
+		}
+
 		controllerutil.RemoveFinalizer(instance, quarantineFinalizer)
 
 		return true, nil
@@ -164,19 +261,57 @@ func (r *QuarantineReconciler) handleFinalizer(instance *v1alpha1.Quarantine, ob
 	return false, nil
 }
 
-func (r *QuarantineReconciler) syncStatus(ctx context.Context, instance *v1alpha1.Quarantine, reqLogger logr.Logger, stats metav1.ConditionStatus, reason, message string) (ctrl.Result, error) {
+// reportWaves returns a reporter which writes the waves to the status while nodes are drained since a drain blocks the reconcile
+func (r *QuarantineReconciler) reportWaves(instance *v1alpha1.Quarantine, reqLogger logr.Logger) func([]v1alpha1.WaveStatus) {
+	return func(waves []v1alpha1.WaveStatus) {
 
-	if meta.IsStatusConditionPresentAndEqual(instance.Status.Conditions, quarantineStatusKey, stats) && instance.Status.Conditions[0].Message == message {
+		instance.Status.Waves = waves
+
+		if err := r.Status().Update(context.Background(), instance); err != nil {
+			reqLogger.Error(err, "error on writing wave progress")
+		}
+	}
+}
+
+func (r *QuarantineReconciler) syncStatus(ctx context.Context, instance *v1alpha1.Quarantine, q *quarantine.Quarantine, reqLogger logr.Logger, stats metav1.ConditionStatus, reason, message string) (ctrl.Result, error) {
+
+	status := instance.Status.DeepCopy()
+	q.UpdateStatus(status)
+
+	if meta.IsStatusConditionPresentAndEqual(instance.Status.Conditions, quarantineStatusKey, stats) && instance.Status.Conditions[0].Message == message &&
+		equality.Semantic.DeepEqual(*status, instance.Status) {
 		reqLogger.Info("Don't reconcile quarantine resource after sync.")
+
+		if reason == quarantine.ReasonDryRun {
+			return ctrl.Result{}, nil
+		}
+
 		return ctrl.Result{
 			Requeue:      true,
 			RequeueAfter: 10 * time.Second,
 		}, nil
 	}
 
-	condition := metav1.Condition{Type: quarantineStatusKey, Status: stats, LastTransitionTime: metav1.Time{Time: time.Now()}, Reason: reason, Message: message}
+	if stats == metav1.ConditionFalse && !q.DryRun {
+		if r.Recorder != nil {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, reason, message)
+		}
+
+		r.notify(q.Client, instance, notifier.Event{Type: notifier.EventFailed, Nodes: status.Nodes, Message: reason + ": " + message}, reqLogger)
+	}
+
+	if !q.DryRun {
+		r.notifyNodeChanges(q.Client, instance, instance.Status.Nodes, status.Nodes, reqLogger)
+	}
+
+	instance.Status = *status
+	condition := metav1.Condition{Type: quarantineStatusKey, Status: stats, LastTransitionTime: metav1.Time{Time: time.Now()}, Reason: reason, Message: message, ObservedGeneration: instance.Generation}
 	meta.SetStatusCondition(&instance.Status.Conditions, condition)
 
+	if !q.DryRun {
+		meta.SetStatusCondition(&instance.Status.Conditions, degradedCondition(instance.Status.DegradedWorkloads))
+	}
+
 	if err := r.Status().Update(ctx, instance); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -185,6 +320,48 @@ func (r *QuarantineReconciler) syncStatus(ctx context.Context, instance *v1alpha
 	return ctrl.Result{}, nil
 }
 
+// failureReason represents the reason of a failed reconcile which is the guard if the quarantine was blocked by one
+func failureReason(err error, reason string) string {
+
+	var blocked *quarantine.BlockedError
+
+	if stderrors.As(err, &blocked) {
+		return blocked.Reason
+	}
+
+	return reason
+}
+
+func degradedMessage(q *quarantine.Quarantine) string {
+	return fmt.Sprintf("%d workloads of evicted pods are not ready again", len(q.Degraded))
+}
+
+// degradedCondition represents if workloads of evicted pods are not ready again on other nodes
+func degradedCondition(workloads []v1alpha1.DegradedWorkload) metav1.Condition {
+
+	condition := metav1.Condition{
+		Type:               quarantineDegradedKey,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             "WorkloadsReady",
+		Message:            "all workloads of evicted pods are ready",
+	}
+
+	if len(workloads) > 0 {
+		names := []string{}
+
+		for _, w := range workloads {
+			names = append(names, fmt.Sprintf("%s %s/%s (%d/%d ready)", strings.ToLower(w.Kind), w.Namespace, w.Name, w.ReadyReplicas, w.Replicas))
+		}
+
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "WorkloadsNotReady"
+		condition.Message = strings.Join(names, ", ")
+	}
+
+	return condition
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *QuarantineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
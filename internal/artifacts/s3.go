@@ -0,0 +1,153 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const s3Service = "s3"
+const s3Algorithm = "AWS4-HMAC-SHA256"
+const s3SignedHeaders = "host;x-amz-content-sha256;x-amz-date"
+const s3DefaultRegion = "us-east-1"
+
+// NewS3Sink represents an initialization of a sink for an S3 compatible object storage
+func NewS3Sink(endpoint, bucket, region, accessKeyID, secretAccessKey string, insecure bool) *S3Sink {
+
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
+	}
+
+	return &S3Sink{
+		Endpoint:        strings.TrimSuffix(endpoint, "/"),
+		Bucket:          bucket,
+		Region:          region,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		Client: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+		},
+	}
+}
+
+// Upload represents putting an object with the given key into the bucket
+func (s *S3Sink) Upload(ctx context.Context, key string, content []byte) error {
+
+	var endpoint *url.URL
+	var err error
+
+	if endpoint, err = url.Parse(s.Endpoint); err != nil {
+		return err
+	}
+
+	// path style requests work with every S3 compatible storage like a local MinIO
+	path := "/" + s.Bucket + "/" + encodeKey(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint.Scheme+"://"+endpoint.Host+path, bytes.NewReader(content))
+
+	if err != nil {
+		return err
+	}
+
+	s.sign(req, path, content, time.Now().UTC())
+
+	resp, err := s.Client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return errors.New("upload of " + key + " failed: " + resp.Status + " " + string(body))
+	}
+
+	return nil
+}
+
+func (s *S3Sink) sign(req *http.Request, path string, content []byte, now time.Time) {
+
+	payloadHash := hashHex(content)
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + s.Region + "/" + s3Service + "/aws4_request"
+
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	req.Header.Set("x-amz-date", amzDate)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		"",
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		s3SignedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.AccessKeyID, scope, s3SignedHeaders, signature))
+}
+
+func hashHex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// encodeKey escapes everything except unreserved characters and slashes as required for signing
+func encodeKey(key string) string {
+
+	var b strings.Builder
+
+	for _, c := range []byte(key) {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+
+	return b.String()
+}
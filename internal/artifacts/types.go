@@ -0,0 +1,19 @@
+package artifacts
+
+import "net/http"
+
+// Artifact represents a file collected for a quarantine
+type Artifact struct {
+	Name    string
+	Content []byte
+}
+
+// S3Sink represents an S3 compatible object storage artifacts are uploaded to
+type S3Sink struct {
+	Endpoint        string
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Client          *http.Client
+}
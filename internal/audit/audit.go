@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+)
+
+const (
+	// VerbCreate represents the creation of an object
+	VerbCreate = "create"
+	// VerbUpdate represents a full update of an object
+	VerbUpdate = "update"
+	// VerbPatch represents a patch of an object
+	VerbPatch = "patch"
+	// VerbEvict represents the eviction of a pod
+	VerbEvict = "evict"
+	// VerbDelete represents the deletion of an object
+	VerbDelete = "delete"
+)
+
+// Record represents a single mutation of the cluster.
+// Diff is the merge patch from the object before to after the mutation, Revert is the merge patch back.
+type Record struct {
+	Sequence  int             `json:"sequence"`
+	Timestamp time.Time       `json:"timestamp"`
+	Actor     string          `json:"actor"`
+	Verb      string          `json:"verb"`
+	Kind      string          `json:"kind"`
+	Namespace string          `json:"namespace,omitempty"`
+	Name      string          `json:"name"`
+	Diff      json.RawMessage `json:"diff,omitempty"`
+	Revert    json.RawMessage `json:"revert,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// Trail represents the records of an actor which are not persisted yet
+type Trail struct {
+	Actor   string
+	mu      sync.Mutex
+	pending []Record
+}
+
+// NewTrail represents initialization of an empty trail for an actor
+func NewTrail(actor string) *Trail {
+	return &Trail{
+		Actor:   actor,
+		pending: []Record{},
+	}
+}
+
+// Add represents recording a mutation of an object. before is nil for created and after is nil for removed objects.
+func (t *Trail) Add(verb, kind, namespace, name string, before, after interface{}) {
+
+	if t == nil {
+		return
+	}
+
+	r := Record{
+		Timestamp: time.Now().UTC(),
+		Actor:     t.Actor,
+		Verb:      verb,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+	}
+
+	// a failed diff still records that the object was changed
+	r.Diff, _ = Diff(before, after)
+	r.Revert, _ = Diff(after, before)
+
+	// drain evicts pods concurrently
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending = append(t.pending, r)
+}
+
+// Pending represents a copy of the records which are not persisted yet
+func (t *Trail) Pending() []Record {
+
+	if t == nil {
+		return []Record{}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]Record{}, t.pending...)
+}
+
+// Diff represents the json merge patch which turns before into after
+func Diff(before, after interface{}) (json.RawMessage, error) {
+
+	original, err := marshal(before)
+
+	if err != nil {
+		return nil, err
+	}
+
+	modified, err := marshal(after)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if modified == nil {
+		return json.RawMessage("null"), nil
+	}
+
+	if original == nil {
+		return modified, nil
+	}
+
+	return jsonpatch.CreateMergePatch(original, modified)
+}
+
+// marshal returns the object without managed fields which only add noise to the diff
+func marshal(obj interface{}) ([]byte, error) {
+
+	if obj == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(obj)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if string(raw) == "null" {
+		return nil, nil
+	}
+
+	fields := map[string]interface{}{}
+
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	if metadata, ok := fields["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,250 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ConfigMapPrefix is the name prefix of the config maps which store the records of a trail
+const ConfigMapPrefix = "quarantine-audit-"
+
+// MaxSegmentBytes limits the size of the records in a single config map which are limited to 1MiB
+const MaxSegmentBytes = 512 * 1024
+
+const labelPrefix = "ops.soer3n.info/"
+const nameLabelKey = labelPrefix + "name"
+const auditLabelKey = labelPrefix + "audit"
+const auditLabelValue = "true"
+const segmentAnnotation = labelPrefix + "segment"
+
+// Flush represents appending the pending records to the config maps of a trail.
+// Records are never changed once written, full config maps are sealed as immutable and a new segment is started.
+func (t *Trail) Flush(c kubernetes.Interface, namespace, name string) error {
+
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.pending) == 0 {
+		return nil
+	}
+
+	segments, err := listSegments(c, namespace, name)
+
+	if err != nil {
+		return err
+	}
+
+	sequence := 0
+	stored := true
+	var current *corev1.ConfigMap
+
+	for i := range segments {
+		sequence += len(segments[i].Data)
+		current = &segments[i]
+	}
+
+	if current == nil {
+		current = newSegment(namespace, name, 0)
+		stored = false
+	} else if isSealed(current) {
+		current = newSegment(namespace, name, segmentIndex(current)+1)
+		stored = false
+	}
+
+	for len(t.pending) > 0 {
+
+		r := t.pending[0]
+		r.Sequence = sequence
+		raw, err := marshalRecord(r)
+
+		if err != nil {
+			return err
+		}
+
+		if segmentSize(current)+len(raw) > MaxSegmentBytes && len(current.Data) > 0 {
+
+			if err := sealSegment(c, current); err != nil {
+				return err
+			}
+
+			current = newSegment(namespace, name, segmentIndex(current)+1)
+			stored = false
+		}
+
+		current.Data[recordKey(sequence)] = string(raw)
+
+		if current, err = saveSegment(c, current, stored); err != nil {
+			return err
+		}
+
+		stored = true
+
+		t.pending = t.pending[1:]
+		sequence++
+	}
+
+	return nil
+}
+
+// Load represents reading all records of a trail ordered by their sequence
+func Load(c kubernetes.Interface, namespace, name string) ([]Record, error) {
+
+	segments, err := listSegments(c, namespace, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	records := []Record{}
+
+	for _, cm := range segments {
+		for _, raw := range cm.Data {
+
+			r := Record{}
+
+			if err := json.Unmarshal([]byte(raw), &r); err != nil {
+				return nil, err
+			}
+
+			records = append(records, r)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Sequence < records[j].Sequence
+	})
+
+	return records, nil
+}
+
+func listSegments(c kubernetes.Interface, namespace, name string) ([]corev1.ConfigMap, error) {
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: nameLabelKey + "=" + name + "," + auditLabelKey + "=" + auditLabelValue,
+	}
+
+	list, err := c.CoreV1().ConfigMaps(namespace).List(context.TODO(), listOpts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	segments := list.Items
+
+	for i := range segments {
+		if segments[i].Data == nil {
+			segments[i].Data = map[string]string{}
+		}
+	}
+
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segmentIndex(&segments[i]) < segmentIndex(&segments[j])
+	})
+
+	return segments, nil
+}
+
+func newSegment(namespace, name string, index int) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ConfigMapPrefix + name + "-" + strconv.Itoa(index),
+			Namespace: namespace,
+			Labels: map[string]string{
+				nameLabelKey:  name,
+				auditLabelKey: auditLabelValue,
+			},
+			Annotations: map[string]string{
+				segmentAnnotation: strconv.Itoa(index),
+			},
+		},
+		Data: map[string]string{},
+	}
+}
+
+func saveSegment(c kubernetes.Interface, cm *corev1.ConfigMap, stored bool) (*corev1.ConfigMap, error) {
+
+	var saved *corev1.ConfigMap
+	var err error
+
+	if !stored {
+		saved, err = c.CoreV1().ConfigMaps(cm.ObjectMeta.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+	} else {
+		saved, err = c.CoreV1().ConfigMaps(cm.ObjectMeta.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if saved.Data == nil {
+		saved.Data = map[string]string{}
+	}
+
+	return saved, nil
+}
+
+func sealSegment(c kubernetes.Interface, cm *corev1.ConfigMap) error {
+
+	immutable := true
+	cm.Immutable = &immutable
+
+	_, err := c.CoreV1().ConfigMaps(cm.ObjectMeta.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	return err
+}
+
+func isSealed(cm *corev1.ConfigMap) bool {
+	return cm.Immutable != nil && *cm.Immutable
+}
+
+func segmentIndex(cm *corev1.ConfigMap) int {
+
+	index, err := strconv.Atoi(cm.ObjectMeta.Annotations[segmentAnnotation])
+
+	if err != nil {
+		return 0
+	}
+
+	return index
+}
+
+func segmentSize(cm *corev1.ConfigMap) int {
+
+	size := 0
+
+	for k, v := range cm.Data {
+		size += len(k) + len(v)
+	}
+
+	return size
+}
+
+// marshalRecord drops the diffs of records which would not fit into an empty segment
+func marshalRecord(r Record) ([]byte, error) {
+
+	raw, err := json.Marshal(r)
+
+	if err != nil || len(raw) <= MaxSegmentBytes/2 {
+		return raw, err
+	}
+
+	r.Diff = nil
+	r.Revert = nil
+	r.Truncated = true
+
+	return json.Marshal(r)
+}
+
+func recordKey(sequence int) string {
+	return fmt.Sprintf("%08d.json", sequence)
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/soer3n/incident-operator/internal/audit"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+const auditOutputJSON = "json"
+const auditOutputJSONLines = "jsonl"
+const auditOutputTable = "table"
+
+// ExportAudit represents printing the audit trail of a quarantine
+func ExportAudit(namespace, name, output string) error {
+	return WriteAudit(os.Stdout, utils.GetTypedKubernetesClient(), namespace, name, output)
+}
+
+// WriteAudit represents writing all audit records of a quarantine ordered by their sequence
+func WriteAudit(w io.Writer, c kubernetes.Interface, namespace, name, output string) error {
+
+	records, err := audit.Load(c, namespace, name)
+
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case auditOutputJSON:
+		raw, err := json.MarshalIndent(records, "", "  ")
+
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(raw))
+		return err
+	case auditOutputJSONLines:
+		enc := json.NewEncoder(w)
+
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case auditOutputTable:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "SEQUENCE\tTIMESTAMP\tACTOR\tVERB\tOBJECT")
+
+		for _, r := range records {
+			object := r.Kind + "/" + r.Name
+
+			if r.Namespace != "" {
+				object = r.Kind + "/" + r.Namespace + "/" + r.Name
+			}
+
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", r.Sequence, r.Timestamp.Format("2006-01-02T15:04:05Z07:00"), r.Actor, r.Verb, object)
+		}
+
+		return tw.Flush()
+	}
+
+	return errors.New("unknown output format " + output + ", expected json, jsonl or table")
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/soer3n/incident-operator/internal/evidence"
+)
+
+// VerifyEvidence represents checking a downloaded evidence bundle against the public key of the signing key
+func VerifyEvidence(bundle, publicKeyPath string) error {
+
+	data, err := ioutil.ReadFile(publicKeyPath)
+
+	if err != nil {
+		return err
+	}
+
+	key, err := evidence.ParsePublicKey(data)
+
+	if err != nil {
+		return err
+	}
+
+	report, err := evidence.VerifyBundle(bundle, key)
+
+	for _, line := range report {
+		fmt.Println(line)
+	}
+
+	return err
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/cmd/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+const (
+	planOutputTable    = "table"
+	planOutputJSON     = "json"
+	planOutputMarkdown = "markdown"
+)
+
+// PlanQuarantine represents previewing the actions of a quarantine manifest against the live cluster
+func PlanQuarantine(path, output string) error {
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	s := &v1alpha1.Quarantine{}
+
+	if err = yaml.Unmarshal(data, s); err != nil {
+		return err
+	}
+
+	typedClient := utils.GetTypedKubernetesClient()
+	factory := util.NewFactory(genericclioptions.NewConfigFlags(false))
+
+	return PreviewQuarantine(os.Stdout, s, typedClient, factory, output)
+}
+
+// PreviewQuarantine represents computing the plan of a quarantine like the dry run of the controller.
+// A quarantine the controller would pause due to the safety guards is reported as error instead of a plan.
+func PreviewQuarantine(w io.Writer, s *v1alpha1.Quarantine, c kubernetes.Interface, f util.Factory, output string) error {
+
+	// the preview must never touch the cluster
+	s.Spec.DryRun = true
+
+	q, err := quarantine.New(s, c, f, ctrl.Log.WithName("plan"))
+
+	if err != nil {
+		return err
+	}
+
+	if err = q.CheckSafety(); err != nil {
+		return fmt.Errorf("quarantine violates cluster safety guards: %w", err)
+	}
+
+	if err = q.Prepare(); err != nil {
+		return err
+	}
+
+	if err = q.Start(); err != nil {
+		return err
+	}
+
+	return WritePlan(w, q.Plan.Actions, output)
+}
+
+// WritePlan represents rendering planned actions grouped by node as table, json or markdown
+func WritePlan(w io.Writer, actions []v1alpha1.PlannedAction, output string) error {
+
+	sorted := make([]v1alpha1.PlannedAction, len(actions))
+	copy(sorted, actions)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Node < sorted[j].Node
+	})
+
+	switch output {
+	case planOutputTable, "":
+		return writePlanTable(w, sorted)
+	case planOutputJSON:
+		return writePlanJSON(w, sorted)
+	case planOutputMarkdown:
+		return writePlanMarkdown(w, sorted)
+	}
+
+	return errors.New("unknown output format " + output)
+}
+
+func writePlanTable(w io.Writer, actions []v1alpha1.PlannedAction) error {
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tKIND\tTARGET\tDETAIL")
+
+	for _, a := range actions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Node, a.Kind, a.Target, a.Detail)
+	}
+
+	return tw.Flush()
+}
+
+func writePlanJSON(w io.Writer, actions []v1alpha1.PlannedAction) error {
+
+	nodes := map[string][]v1alpha1.PlannedAction{}
+
+	for _, a := range actions {
+		nodes[a.Node] = append(nodes[a.Node], a)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(nodes)
+}
+
+func writePlanMarkdown(w io.Writer, actions []v1alpha1.PlannedAction) error {
+
+	node := ""
+
+	for i, a := range actions {
+
+		if i == 0 || a.Node != node {
+			node = a.Node
+
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+
+			fmt.Fprintf(w, "### %s\n\n", node)
+			fmt.Fprintln(w, "| Kind | Target | Detail |")
+			fmt.Fprintln(w, "| --- | --- | --- |")
+		}
+
+		if _, err := fmt.Fprintf(w, "| %s | %s | %s |\n", a.Kind, a.Target, strings.ReplaceAll(a.Detail, "|", "\\|")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,432 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/kubectl/pkg/util/term"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+)
+
+var pluginResourceTypes = map[string]string{
+	"deploy":      "deployment",
+	"deployment":  "deployment",
+	"deployments": "deployment",
+	"ds":          "daemonset",
+	"daemonset":   "daemonset",
+	"daemonsets":  "daemonset",
+}
+
+// Plugin represents the kubectl quarantine plugin operating on quarantine resources of a namespace
+type Plugin struct {
+	Client    client.Client
+	Clientset kubernetes.Interface
+	Config    *rest.Config
+	Namespace string
+	genericclioptions.IOStreams
+}
+
+// StartOptions represents the flags of a quarantine started by the plugin
+type StartOptions struct {
+	Name       string
+	Isolate    []string
+	Evict      bool
+	Debug      bool
+	DebugImage string
+	DryRun     bool
+}
+
+// NewPlugin represents initialization of the plugin by the kubeconfig flags of kubectl
+func NewPlugin(flags *genericclioptions.ConfigFlags, streams genericclioptions.IOStreams) (*Plugin, error) {
+
+	config, err := flags.ToRESTConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, _, err := flags.ToRawKubeConfigLoader().Namespace()
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := runtime.NewScheme()
+
+	if err = v1alpha1.AddToScheme(s); err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(config, client.Options{Scheme: s})
+
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Plugin{
+		Client:    c,
+		Clientset: clientset,
+		Config:    config,
+		Namespace: namespace,
+		IOStreams: streams,
+	}, nil
+}
+
+// ParseIsolateResources represents parsing of workloads given as [namespace/]type/name into resources to isolate
+func ParseIsolateResources(values []string, namespace string) ([]v1alpha1.Resource, error) {
+
+	resources := []v1alpha1.Resource{}
+
+	for _, v := range values {
+
+		parts := strings.Split(v, "/")
+		resourceNamespace := namespace
+
+		if len(parts) == 3 {
+			resourceNamespace = parts[0]
+			parts = parts[1:]
+		}
+
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, errors.New("invalid workload " + v + ", expected [namespace/]type/name")
+		}
+
+		resourceType, ok := pluginResourceTypes[parts[0]]
+
+		if !ok {
+			return nil, errors.New("unsupported workload type " + parts[0] + ", expected deployment or daemonset")
+		}
+
+		resources = append(resources, v1alpha1.Resource{
+			Type:      resourceType,
+			Name:      parts[1],
+			Namespace: resourceNamespace,
+		})
+	}
+
+	return resources, nil
+}
+
+// Start represents creating a quarantine resource for a node
+func (p *Plugin) Start(node string, opts StartOptions) error {
+
+	resources, err := ParseIsolateResources(opts.Isolate, p.Namespace)
+
+	if err != nil {
+		return err
+	}
+
+	name := opts.Name
+
+	if name == "" {
+		name = "quarantine-" + node
+	}
+
+	s := &v1alpha1.Quarantine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: p.Namespace,
+		},
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun: opts.DryRun,
+			Debug: v1alpha1.Debug{
+				Enabled: opts.Debug,
+				Image:   opts.DebugImage,
+			},
+			Nodes: []v1alpha1.Node{
+				{
+					Name:    node,
+					Isolate: opts.Evict,
+				},
+			},
+			Resources: resources,
+		},
+	}
+
+	if err = p.Client.Create(context.TODO(), s); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.Out, "quarantine %s/%s created\n", p.Namespace, name)
+	return nil
+}
+
+// Stop represents deleting a quarantine resource which reverts all of its nodes
+func (p *Plugin) Stop(name string) error {
+
+	s, err := p.findQuarantine(name, "")
+
+	if err != nil {
+		return err
+	}
+
+	if err = p.Client.Delete(context.TODO(), s); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.Out, "quarantine %s/%s deleted\n", s.ObjectMeta.Namespace, s.ObjectMeta.Name)
+	return nil
+}
+
+// ReleaseNode represents removing a single node from the spec of a quarantine.
+// The mutating webhook marks the removed node by the revert annotation so that the controller releases it.
+func (p *Plugin) ReleaseNode(name, node string) error {
+
+	s, err := p.findQuarantine(name, node)
+
+	if err != nil {
+		return err
+	}
+
+	nodes := []v1alpha1.Node{}
+
+	for _, n := range s.Spec.Nodes {
+		if n.Name != node {
+			nodes = append(nodes, n)
+		}
+	}
+
+	if len(nodes) == len(s.Spec.Nodes) {
+		return errors.New("node " + node + " is not part of quarantine " + s.ObjectMeta.Name)
+	}
+
+	if len(nodes) == 0 {
+		return errors.New("node " + node + " is the last node of quarantine " + s.ObjectMeta.Name + ", use stop instead")
+	}
+
+	s.Spec.Nodes = nodes
+
+	if err = p.Client.Update(context.TODO(), s); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.Out, "node %s marked for release from quarantine %s/%s\n", node, s.ObjectMeta.Namespace, s.ObjectMeta.Name)
+	return nil
+}
+
+// Status represents printing nodes, isolated pods and debug pods of quarantines
+func (p *Plugin) Status(name string) error {
+
+	quarantines := []v1alpha1.Quarantine{}
+
+	if name != "" {
+		s, err := p.findQuarantine(name, "")
+
+		if err != nil {
+			return err
+		}
+
+		quarantines = append(quarantines, *s)
+	} else {
+		list := &v1alpha1.QuarantineList{}
+
+		if err := p.Client.List(context.TODO(), list, client.InNamespace(p.Namespace)); err != nil {
+			return err
+		}
+
+		quarantines = list.Items
+	}
+
+	if len(quarantines) == 0 {
+		fmt.Fprintf(p.Out, "no quarantines found in namespace %s\n", p.Namespace)
+		return nil
+	}
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: quarantine.QuarantinePodLabelPrefix + quarantine.QuarantinePodLabelKey + "=true",
+	}
+
+	pods, err := p.Clientset.CoreV1().Pods("").List(context.TODO(), listOpts)
+
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(p.Out, 0, 8, 2, ' ', 0)
+
+	for i, s := range quarantines {
+
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+
+		fmt.Fprintf(tw, "QUARANTINE\t%s/%s\n", s.ObjectMeta.Namespace, s.ObjectMeta.Name)
+
+		for _, c := range s.Status.Conditions {
+			fmt.Fprintf(tw, "CONDITION\t%s=%s\t%s\t%s\n", c.Type, c.Status, c.Reason, c.Message)
+		}
+
+		fmt.Fprintln(tw, "NODE\tSCHEDULABLE\tISOLATED PODS\tDEBUG POD")
+
+		for _, n := range s.Spec.Nodes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", n.Name, p.nodeSchedulable(n.Name), strings.Join(isolatedPods(&s, pods.Items, n.Name), ","), debugPodStatus(&s, pods.Items, n.Name))
+		}
+	}
+
+	return tw.Flush()
+}
+
+// Debug represents opening an interactive shell in the debug pod of a node
+func (p *Plugin) Debug(name, node string, command []string) error {
+
+	s, err := p.findQuarantine(name, node)
+
+	if err != nil {
+		return err
+	}
+
+	namespace, podName, container := quarantine.DebugPodRef(s, node)
+
+	if _, err = p.Clientset.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{}); err != nil {
+		return errors.New("no debug pod found for node " + node + ": " + err.Error())
+	}
+
+	if len(command) == 0 {
+		command = []string{"sh", "-c", "command -v bash >/dev/null && exec bash || exec sh"}
+	}
+
+	t := term.TTY{
+		In:  p.In,
+		Out: p.Out,
+		Raw: true,
+	}
+
+	req := p.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     true,
+			Stdout:    true,
+			TTY:       t.IsTerminalIn(),
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(p.Config, "POST", req.URL())
+
+	if err != nil {
+		return err
+	}
+
+	return t.Safe(func() error {
+		return executor.Stream(remotecommand.StreamOptions{
+			Stdin:             p.In,
+			Stdout:            p.Out,
+			Tty:               t.IsTerminalIn(),
+			TerminalSizeQueue: t.MonitorSize(t.GetSize()),
+		})
+	})
+}
+
+// findQuarantine returns the quarantine with the given name or the only one in the namespace containing the node
+func (p *Plugin) findQuarantine(name, node string) (*v1alpha1.Quarantine, error) {
+
+	if name != "" {
+		s := &v1alpha1.Quarantine{}
+
+		if err := p.Client.Get(context.TODO(), client.ObjectKey{Namespace: p.Namespace, Name: name}, s); err != nil {
+			return nil, err
+		}
+
+		return s, nil
+	}
+
+	list := &v1alpha1.QuarantineList{}
+
+	if err := p.Client.List(context.TODO(), list, client.InNamespace(p.Namespace)); err != nil {
+		return nil, err
+	}
+
+	found := []v1alpha1.Quarantine{}
+
+	for _, s := range list.Items {
+		if node == "" || hasNode(&s, node) {
+			found = append(found, s)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, errors.New("no matching quarantine found in namespace " + p.Namespace)
+	}
+
+	if len(found) > 1 {
+		return nil, errors.New("multiple quarantines found in namespace " + p.Namespace + ", set --name")
+	}
+
+	return &found[0], nil
+}
+
+func (p *Plugin) nodeSchedulable(name string) string {
+
+	node, err := p.Clientset.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+
+	if err != nil {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%t", !node.Spec.Unschedulable)
+}
+
+func hasNode(s *v1alpha1.Quarantine, node string) bool {
+
+	for _, n := range s.Spec.Nodes {
+		if n.Name == node {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isolatedPods(s *v1alpha1.Quarantine, pods []corev1.Pod, node string) []string {
+
+	_, debugPod, _ := quarantine.DebugPodRef(s, node)
+	names := []string{}
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName == node && pod.ObjectMeta.Name != debugPod {
+			names = append(names, pod.ObjectMeta.Namespace+"/"+pod.ObjectMeta.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		return []string{"<none>"}
+	}
+
+	return names
+}
+
+func debugPodStatus(s *v1alpha1.Quarantine, pods []corev1.Pod, node string) string {
+
+	namespace, name, _ := quarantine.DebugPodRef(s, node)
+
+	for _, pod := range pods {
+		if pod.ObjectMeta.Namespace == namespace && pod.ObjectMeta.Name == name {
+			return namespace + "/" + name + " (" + string(pod.Status.Phase) + ")"
+		}
+	}
+
+	return "<none>"
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/soer3n/incident-operator/internal/audit"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+// releaseAuditName is the name break-glass releases are recorded under in the audit namespace
+const releaseAuditName = "break-glass"
+
+// ReleaseNodes represents reverting quarantine artifacts on nodes directly when the operator is not available
+func ReleaseNodes(nodes []string, dryRun bool, auditNamespace string) error {
+
+	typedClient := utils.GetTypedKubernetesClient()
+	trail := audit.NewTrail(auditNamespace + "/" + releaseAuditName)
+
+	plan, err := quarantine.Release(typedClient, nodes, dryRun, trail)
+
+	if flushErr := trail.Flush(typedClient, auditNamespace, releaseAuditName); flushErr != nil {
+		fmt.Fprintln(os.Stderr, "cannot write audit records: "+flushErr.Error())
+	}
+
+	if len(plan.Actions) == 0 {
+		fmt.Println("no quarantine artifacts found")
+		return err
+	}
+
+	if dryRun {
+		fmt.Println("dry run, nothing was changed")
+	}
+
+	if writeErr := WritePlan(os.Stdout, plan.Actions, planOutputTable); writeErr != nil {
+		return writeErr
+	}
+
+	return err
+}
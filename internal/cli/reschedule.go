@@ -6,7 +6,6 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/soer3n/incident-operator/internal/utils"
@@ -14,9 +13,7 @@ import (
 )
 
 const (
-	rescheduleStrategy  = "evict"
-	evictionKind        = "Eviction"
-	evictionSubresource = "pods/eviction"
+	rescheduleStrategy = "evict"
 )
 
 // RescheduleQuarantineController represents descheduling of quarantine controller if needed due to validation
@@ -50,7 +47,7 @@ func RescheduleQuarantineController(excludedNodes []string) error {
 
 	pod.Spec.NodeSelector[quarantineControllerLabelKey] = quarantineControllerLabelValue
 
-	if policyGroupVersion, err = supportEviction(discoveryClient); err != nil {
+	if policyGroupVersion, err = utils.SupportEviction(discoveryClient); err != nil {
 		return err
 	}
 
@@ -98,32 +95,3 @@ func labelNodes(c kubernetes.Interface, excludedNodes []string) error {
 
 	return nil
 }
-
-func supportEviction(client discovery.ServerResourcesInterface) (string, error) {
-	groupList, _, err := client.ServerGroupsAndResources()
-	if err != nil {
-		return "", err
-	}
-	foundPolicyGroup := false
-	var policyGroupVersion string
-	for _, group := range groupList {
-		if group.Name == "policy" {
-			foundPolicyGroup = true
-			policyGroupVersion = group.PreferredVersion.GroupVersion
-			break
-		}
-	}
-	if !foundPolicyGroup {
-		return "", nil
-	}
-	resourceList, err := client.ServerResourcesForGroupVersion("v1")
-	if err != nil {
-		return "", err
-	}
-	for _, resource := range resourceList.APIResources {
-		if resource.Name == evictionSubresource && resource.Kind == evictionKind {
-			return policyGroupVersion, nil
-		}
-	}
-	return "", nil
-}
@@ -0,0 +1,207 @@
+package evidence
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestFile represents the name of the manifest in an evidence bundle
+const ManifestFile = "manifest.json"
+
+// SignatureFile represents the name of the base64 encoded manifest signature in an evidence bundle
+const SignatureFile = "manifest.sig"
+
+// Append represents adding a hashed artifact as new entry chained to the last one
+func (m *Manifest) Append(name string, content []byte, timestamp time.Time) Entry {
+
+	previous := ""
+
+	if len(m.Entries) > 0 {
+		previous = m.Entries[len(m.Entries)-1].Hash
+	}
+
+	entry := Entry{
+		Name:      name,
+		SHA256:    hashHex(content),
+		Timestamp: timestamp.UTC(),
+		Previous:  previous,
+	}
+
+	entry.Hash = entry.chainHash()
+	m.Entries = append(m.Entries, entry)
+
+	return entry
+}
+
+// Verify represents checking that no entry of the chain was changed, removed or reordered
+func (m Manifest) Verify() error {
+
+	previous := ""
+
+	for i, entry := range m.Entries {
+
+		if entry.Previous != previous {
+			return errors.New("entry " + entry.Name + " is not chained to its predecessor")
+		}
+
+		if entry.Hash != entry.chainHash() {
+			return errors.New("hash of entry " + entry.Name + " does not match")
+		}
+
+		previous = m.Entries[i].Hash
+	}
+
+	return nil
+}
+
+// Sign represents signing the raw manifest with an ed25519 private key
+func Sign(manifest []byte, key ed25519.PrivateKey) []byte {
+	signature := ed25519.Sign(key, manifest)
+	return []byte(base64.StdEncoding.EncodeToString(signature))
+}
+
+// ParsePrivateKey represents parsing a PEM encoded PKCS8 ed25519 private key
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("no pem encoded private key found")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+
+	if !ok {
+		return nil, errors.New("private key is not an ed25519 key")
+	}
+
+	return privateKey, nil
+}
+
+// ParsePublicKey represents parsing a PEM encoded PKIX ed25519 public key
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("no pem encoded public key found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := key.(ed25519.PublicKey)
+
+	if !ok {
+		return nil, errors.New("public key is not an ed25519 key")
+	}
+
+	return publicKey, nil
+}
+
+// VerifyBundle represents checking signature, chain and artifact hashes of a downloaded evidence bundle
+func VerifyBundle(dir string, key ed25519.PublicKey) ([]string, error) {
+
+	var manifest Manifest
+	report := []string{}
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+
+	if err != nil {
+		return report, err
+	}
+
+	signature, err := ioutil.ReadFile(filepath.Join(dir, SignatureFile))
+
+	if err != nil {
+		return report, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+
+	if err != nil {
+		return report, err
+	}
+
+	if !ed25519.Verify(key, raw, decoded) {
+		return report, errors.New("signature of manifest is invalid")
+	}
+
+	report = append(report, "signature valid")
+
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return report, err
+	}
+
+	if err := manifest.Verify(); err != nil {
+		return report, err
+	}
+
+	report = append(report, "chain of "+manifest.Quarantine+" valid")
+
+	// an artifact can be uploaded again later, only its last entry has to match the file
+	latest := map[string]int{}
+
+	for i, entry := range manifest.Entries {
+		latest[entry.Name] = i
+	}
+
+	for i, entry := range manifest.Entries {
+
+		if latest[entry.Name] != i {
+			report = append(report, "superseded "+entry.Name)
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(entry.Name)))
+
+		if err != nil {
+			if os.IsNotExist(err) {
+				return report, errors.New("artifact " + entry.Name + " is missing")
+			}
+			return report, err
+		}
+
+		if hashHex(content) != entry.SHA256 {
+			return report, errors.New("artifact " + entry.Name + " was modified")
+		}
+
+		report = append(report, "verified "+entry.Name)
+	}
+
+	return report, nil
+}
+
+func (e Entry) chainHash() string {
+	return hashHex([]byte(strings.Join([]string{
+		e.Previous,
+		e.Name,
+		e.SHA256,
+		e.Timestamp.Format(time.RFC3339Nano),
+	}, "\n")))
+}
+
+func hashHex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
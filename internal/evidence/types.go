@@ -0,0 +1,18 @@
+package evidence
+
+import "time"
+
+// Manifest represents an append-only chain of hashed artifacts collected for a quarantine
+type Manifest struct {
+	Quarantine string  `json:"quarantine"`
+	Entries    []Entry `json:"entries"`
+}
+
+// Entry represents a hashed artifact which is chained to the previous entry of a manifest
+type Entry struct {
+	Name      string    `json:"name"`
+	SHA256    string    `json:"sha256"`
+	Timestamp time.Time `json:"timestamp"`
+	Previous  string    `json:"previous"`
+	Hash      string    `json:"hash"`
+}
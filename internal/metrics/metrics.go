@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "quarantine"
+
+var (
+	// QuarantineDuration represents the time between creation and stop of a quarantine
+	QuarantineDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "duration_seconds",
+		Help:      "Duration of stopped quarantines from creation until all nodes were released.",
+		Buckets:   []float64{60, 300, 900, 1800, 3600, 7200, 14400, 28800, 86400, 259200},
+	})
+
+	// StepDuration represents the latency of a single step on a node
+	StepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "step_duration_seconds",
+		Help:      "Latency of quarantine steps on a node.",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"step"})
+
+	// EvictionFailures represents failed evictions by reason
+	EvictionFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "eviction_failures_total",
+		Help:      "Failed pod evictions and drains by reason.",
+	}, []string{"reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(QuarantineDuration, StepDuration, EvictionFailures)
+}
+
+// ObserveStep represents recording the latency of a step which started at start
+func ObserveStep(step string, start time.Time) {
+	StepDuration.WithLabelValues(step).Observe(time.Since(start).Seconds())
+}
+
+// EvictionFailed represents counting a failed eviction by the reason of the api error
+func EvictionFailed(err error) {
+
+	reason := string(apierrors.ReasonForError(err))
+
+	if reason == "" {
+		reason = "Unknown"
+	}
+
+	EvictionFailures.WithLabelValues(reason).Inc()
+}
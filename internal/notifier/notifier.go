@@ -0,0 +1,173 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+	"summary": summary,
+}
+
+// NewSink represents an initialization of a sink with the default payload of its type if tmpl is empty
+func NewSink(sinkType, url, tmpl string, events []string) (*Sink, error) {
+
+	if url == "" {
+		return nil, errors.New("no url set for " + sinkType + " notification")
+	}
+
+	if tmpl == "" {
+		var ok bool
+
+		if tmpl, ok = defaultTemplates[sinkType]; !ok {
+			return nil, errors.New("unknown notification type " + sinkType)
+		}
+	}
+
+	t, err := template.New(sinkType).Funcs(templateFuncs).Parse(tmpl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sink{
+		Type:     sinkType,
+		URL:      url,
+		Events:   events,
+		Template: t,
+		Client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+// Notify represents sending an event to every subscribed sink
+func (n *Notifier) Notify(ctx context.Context, e Event) error {
+
+	if e.ID == "" {
+		e.ID = newEventID()
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	errs := []error{}
+
+	for _, s := range n.Sinks {
+		if err := s.Notify(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s notification: %w", s.Type, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Notify represents rendering the payload of an event and posting it to the sink
+func (s *Sink) Notify(ctx context.Context, e Event) error {
+
+	if !s.subscribed(e.Type) {
+		return nil
+	}
+
+	var payload bytes.Buffer
+
+	if err := s.Template.Execute(&payload, e); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, &payload)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// structured content mode of the cloudevents http binding
+	if s.Type == SinkCloudEvents {
+		req.Header.Set("Content-Type", "application/cloudevents+json; charset=UTF-8")
+	}
+
+	resp, err := s.Client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return errors.New("unexpected status " + resp.Status)
+	}
+
+	return nil
+}
+
+func (s *Sink) subscribed(eventType string) bool {
+
+	if len(s.Events) == 0 {
+		return true
+	}
+
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func summary(e Event) string {
+
+	quarantine := e.Namespace + "/" + e.Name
+	var text string
+
+	switch e.Type {
+	case EventStarted:
+		text = "Quarantine " + quarantine + " started for nodes " + strings.Join(e.Nodes, ", ")
+	case EventFailed:
+		text = "Quarantine " + quarantine + " failed"
+	case EventNodeAdded:
+		text = "Node " + e.Node + " added to quarantine " + quarantine
+	case EventNodeRemoved:
+		text = "Node " + e.Node + " removed from quarantine " + quarantine
+	case EventReleased:
+		text = "Quarantine " + quarantine + " released nodes " + strings.Join(e.Nodes, ", ")
+	default:
+		text = "Quarantine " + quarantine + " " + e.Type
+	}
+
+	if e.Message != "" {
+		text += ": " + e.Message
+	}
+
+	return text
+}
+
+func newEventID() string {
+
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,38 @@
+package notifier
+
+// default payloads per sink type, every template gets an Event as data
+var defaultTemplates = map[string]string{
+	SinkWebhook: `{
+  "event": {{json .Type}},
+  "namespace": {{json .Namespace}},
+  "name": {{json .Name}},
+  "nodes": {{json .Nodes}},
+  "node": {{json .Node}},
+  "message": {{json .Message}},
+  "time": {{json .Time}}
+}`,
+	SinkSlack: `{
+  "text": {{json (summary .)}}
+}`,
+	SinkTeams: `{
+  "@type": "MessageCard",
+  "@context": "https://schema.org/extensions",
+  "summary": {{json (summary .)}},
+  "themeColor": {{if eq .Type "failed"}}"d70000"{{else}}"0076d7"{{end}},
+  "title": {{json (printf "Quarantine %s/%s" .Namespace .Name)}},
+  "text": {{json (summary .)}}
+}`,
+	SinkCloudEvents: `{
+  "specversion": "1.0",
+  "id": {{json .ID}},
+  "source": {{json (printf "/apis/ops.soer3n.info/v1alpha1/namespaces/%s/quarantines/%s" .Namespace .Name)}},
+  "type": {{json (printf "info.soer3n.ops.quarantine.%s" .Type)}},
+  "subject": {{json .Node}},
+  "time": {{json .Time}},
+  "datacontenttype": "application/json",
+  "data": {
+    "nodes": {{json .Nodes}},
+    "message": {{json .Message}}
+  }
+}`,
+}
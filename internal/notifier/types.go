@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"net/http"
+	"text/template"
+	"time"
+)
+
+// Event types a sink can subscribe to
+const (
+	EventStarted     = "started"
+	EventFailed      = "failed"
+	EventNodeAdded   = "nodeAdded"
+	EventNodeRemoved = "nodeRemoved"
+	EventReleased    = "released"
+)
+
+// Sink types which are supported
+const (
+	SinkWebhook     = "webhook"
+	SinkSlack       = "slack"
+	SinkTeams       = "teams"
+	SinkCloudEvents = "cloudevents"
+)
+
+// Event represents a lifecycle transition of a quarantine
+type Event struct {
+	ID        string
+	Type      string
+	Namespace string
+	Name      string
+	Nodes     []string
+	Node      string
+	Message   string
+	Time      time.Time
+}
+
+// Sink represents a target which is notified about lifecycle transitions
+type Sink struct {
+	Type     string
+	URL      string
+	Events   []string
+	Template *template.Template
+	Client   *http.Client
+}
+
+// Notifier represents a set of sinks which are notified together
+type Notifier struct {
+	Sinks []*Sink
+}
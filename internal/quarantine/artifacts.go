@@ -0,0 +1,157 @@
+package quarantine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/artifacts"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+const artifactSecretAccessKeyID = "accessKeyID"
+const artifactSecretSecretAccessKey = "secretAccessKey"
+const artifactTimestampFormat = "20060102T150405Z"
+
+func (q *Quarantine) addArtifact(name string, content []byte) {
+	q.pendingArtifacts = append(q.pendingArtifacts, artifacts.Artifact{
+		Name:    name,
+		Content: content,
+	})
+}
+
+func (q *Quarantine) addComparisonArtifact(comparison v1alpha1.NodeComparison) {
+
+	content, err := json.MarshalIndent(comparison, "", "  ")
+
+	if err != nil {
+		q.Logger.Error(err, "marshal comparison", "node", comparison.Node)
+		return
+	}
+
+	q.addArtifact("comparisons/"+comparison.Node+".json", content)
+}
+
+func (q *Quarantine) addLogArtifacts() {
+
+	if !q.Logs.Enabled || q.Streamer == nil {
+		return
+	}
+
+	dir := filepath.Join(q.Streamer.Dir, q.Namespace, q.Name)
+	files, err := ioutil.ReadDir(dir)
+
+	if err != nil {
+		if !os.IsNotExist(err) {
+			q.Logger.Error(err, "read log directory")
+		}
+		return
+	}
+
+	for _, f := range files {
+
+		if f.IsDir() {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+
+		if err != nil {
+			q.Logger.Error(err, "read log file", "file", f.Name())
+			continue
+		}
+
+		q.addArtifact("logs/"+f.Name(), content)
+	}
+}
+
+// exportPendingArtifacts represents exporting the collected artifacts without failing the step. The artifacts are only held
+// by this quarantine value, so they are exported before a reconcile returns even if a step failed.
+func (q *Quarantine) exportPendingArtifacts() {
+
+	if q.DryRun {
+		return
+	}
+
+	q.Logger.Info("export artifacts...")
+	if err := q.exportArtifacts(); err != nil {
+		q.Logger.Error(err, "export artifacts")
+	}
+}
+
+func (q *Quarantine) exportArtifacts() error {
+
+	if len(q.pendingArtifacts) == 0 {
+		return nil
+	}
+
+	if err := q.recordEvidence(); err != nil {
+		return err
+	}
+
+	if q.ArtifactSink == nil {
+		q.pendingArtifacts = []artifacts.Artifact{}
+		return nil
+	}
+
+	sink, err := q.getArtifactSink()
+
+	if err != nil {
+		return err
+	}
+
+	prefix := path.Join(q.ArtifactSink.Prefix, q.Namespace, q.Name, q.created.UTC().Format(artifactTimestampFormat))
+
+	for _, a := range q.pendingArtifacts {
+
+		key := path.Join(prefix, a.Name)
+
+		if err := sink.Upload(context.TODO(), key, a.Content); err != nil {
+			return err
+		}
+
+		q.Logger.Info("artifact uploaded", "key", key)
+
+		if !utils.Contains(q.Artifacts, key) {
+			q.Artifacts = append(q.Artifacts, key)
+		}
+	}
+
+	q.pendingArtifacts = []artifacts.Artifact{}
+
+	return nil
+}
+
+func (q *Quarantine) getArtifactSink() (*artifacts.S3Sink, error) {
+
+	var secret *corev1.Secret
+	var err error
+
+	getOpts := metav1.GetOptions{}
+
+	if secret, err = q.Client.CoreV1().Secrets(q.Namespace).Get(context.TODO(), q.ArtifactSink.CredentialsSecret, getOpts); err != nil {
+		return nil, err
+	}
+
+	accessKeyID, ok := secret.Data[artifactSecretAccessKeyID]
+
+	if !ok {
+		return nil, errors.New("secret " + secret.ObjectMeta.Name + " has no key " + artifactSecretAccessKeyID)
+	}
+
+	secretAccessKey, ok := secret.Data[artifactSecretSecretAccessKey]
+
+	if !ok {
+		return nil, errors.New("secret " + secret.ObjectMeta.Name + " has no key " + artifactSecretSecretAccessKey)
+	}
+
+	return artifacts.NewS3Sink(q.ArtifactSink.Endpoint, q.ArtifactSink.Bucket, q.ArtifactSink.Region, string(accessKeyID), string(secretAccessKey), q.ArtifactSink.Insecure), nil
+}
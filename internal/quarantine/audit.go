@@ -0,0 +1,9 @@
+package quarantine
+
+// flushAudit persists the recorded mutations next to the quarantine resource
+func (q *Quarantine) flushAudit() {
+
+	if err := q.Audit.Flush(q.Client, q.Namespace, q.Name); err != nil {
+		q.Logger.Error(err, "write audit records")
+	}
+}
@@ -0,0 +1,476 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+)
+
+// reasons why a pod does not fit on a node, worded like the scheduler does
+const capacityReasonUnschedulable = "node(s) were unschedulable"
+const capacityReasonNotReady = "node(s) were not ready"
+const capacityReasonTaint = "node(s) had untolerated taint"
+const capacityReasonAffinity = "node(s) didn't match node selector"
+const capacityReasonVolume = "node(s) had volume node affinity conflict"
+const capacityReasonSpread = "node(s) didn't match pod topology spread constraints"
+const capacityReasonTooManyPods = "Too many pods"
+const capacityReasonInsufficient = "Insufficient "
+
+// volume zone labels of persistent volumes which are provisioned without node affinity
+var volumeZoneLabels = []string{
+	"topology.kubernetes.io/zone",
+	"failure-domain.beta.kubernetes.io/zone",
+}
+
+// capacityNode represents a remaining node together with the pods which are or would be scheduled on it
+type capacityNode struct {
+	node      *corev1.Node
+	requested corev1.ResourceList
+	pods      []*corev1.Pod
+}
+
+// movingPod represents a pod which is removed from a quarantined node and recreated on another node
+type movingPod struct {
+	node string
+	pod  *corev1.Pod
+}
+
+// checkCapacity represents simulating whether the pods which are moved away from quarantined nodes fit on the remaining nodes.
+// Pods are placed one after another by priority on the first node which fits. Pods which would not fit are recorded and block the quarantine if configured.
+func (q *Quarantine) checkCapacity() error {
+
+	nodes, err := q.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	pods, err := q.Client.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	quarantined := map[string]*Node{}
+
+	for _, n := range q.Nodes {
+		quarantined[n.Name] = n
+	}
+
+	remaining := []*capacityNode{}
+	byName := map[string]*capacityNode{}
+
+	for i := range nodes.Items {
+
+		node := &nodes.Items[i]
+
+		// nodes in soft mode keep their pods and only take new pods if they are not cordoned
+		if n, ok := quarantined[node.ObjectMeta.Name]; ok && (!n.soft() || n.SoftCordon) {
+			continue
+		}
+
+		c := &capacityNode{node: node, requested: corev1.ResourceList{}, pods: []*corev1.Pod{}}
+		remaining = append(remaining, c)
+		byName[node.ObjectMeta.Name] = c
+	}
+
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i].node.ObjectMeta.Name < remaining[j].node.ObjectMeta.Name
+	})
+
+	for i := range pods.Items {
+
+		pod := &pods.Items[i]
+
+		if c, ok := byName[pod.Spec.NodeName]; ok && !podTerminated(pod) {
+			c.add(pod)
+		}
+	}
+
+	moving := []movingPod{}
+
+	for _, n := range q.Nodes {
+
+		list, err := n.movingPods(pods.Items)
+
+		if err != nil {
+			return err
+		}
+
+		for i := range list {
+			moving = append(moving, movingPod{node: n.Name, pod: &list[i]})
+		}
+	}
+
+	sort.SliceStable(moving, func(i, j int) bool {
+		return corev1helpers.PodPriority(moving[i].pod) > corev1helpers.PodPriority(moving[j].pod)
+	})
+
+	q.Unschedulable = []v1alpha1.UnschedulablePod{}
+
+	for _, m := range moving {
+
+		target, reason, err := q.fit(m.pod, remaining)
+
+		if err != nil {
+			return err
+		}
+
+		if target != nil {
+			target.add(m.pod)
+			continue
+		}
+
+		q.Logger.Info("pod would not fit on remaining nodes", "node", m.node, "pod", m.pod.ObjectMeta.Namespace+"/"+m.pod.ObjectMeta.Name, "reason", reason)
+		q.Unschedulable = append(q.Unschedulable, v1alpha1.UnschedulablePod{
+			Node:   m.node,
+			Pod:    m.pod.ObjectMeta.Namespace + "/" + m.pod.ObjectMeta.Name,
+			Reason: reason,
+		})
+
+		if q.Plan != nil {
+			q.Plan.add(m.node, planActionUnschedulable, podTarget(*m.pod), reason)
+		}
+	}
+
+	if q.Capacity.Block && !q.DryRun && len(q.Unschedulable) > 0 {
+		return &BlockedError{Reason: BlockedReasonCapacity, Err: fmt.Errorf("%d pods would not fit on the remaining nodes", len(q.Unschedulable))}
+	}
+
+	return nil
+}
+
+// movingPods returns the pods which are drained, evicted or deleted from the node and recreated by their controller on other nodes
+func (n *Node) movingPods(pods []corev1.Pod) ([]corev1.Pod, error) {
+
+	moving := []corev1.Pod{}
+
+	if n.soft() {
+		return moving, nil
+	}
+
+	workloads, err := n.workloadStrategies()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods {
+
+		if pod.Spec.NodeName != n.Name || podTerminated(&pod) {
+			continue
+		}
+
+		if _, ok := pod.ObjectMeta.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+			continue
+		}
+
+		// daemonset pods and pods without controller are not recreated on other nodes
+		if metav1.GetControllerOf(&pod) == nil || isDaemonSetPod(pod) {
+			continue
+		}
+
+		if excluded, _ := n.Filter.Excludes(pod); excluded {
+			continue
+		}
+
+		if pod.Spec.PriorityClassName == "system-node-critical" {
+			continue
+		}
+
+		switch n.strategyFor(pod, workloads) {
+		case strategyIsolate, strategyKeep:
+			continue
+		}
+
+		moving = append(moving, pod)
+	}
+
+	return moving, nil
+}
+
+// fit returns the first remaining node the pod fits on. Otherwise the reasons of all nodes are summarized.
+func (q *Quarantine) fit(pod *corev1.Pod, remaining []*capacityNode) (*capacityNode, string, error) {
+
+	if len(remaining) == 0 {
+		return nil, "no remaining nodes", nil
+	}
+
+	volumes, err := q.volumeAffinity(pod)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	requests := podRequests(pod)
+	affinity := nodeaffinity.GetRequiredNodeAffinity(pod)
+	reasons := map[string]int{}
+
+	for _, c := range remaining {
+
+		reason, err := c.fits(pod, requests, affinity, volumes, remaining)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		if reason == "" {
+			return c, "", nil
+		}
+
+		reasons[reason]++
+	}
+
+	summary := []string{}
+
+	for reason, count := range reasons {
+		summary = append(summary, fmt.Sprintf("%d %s", count, reason))
+	}
+
+	sort.Strings(summary)
+
+	return nil, fmt.Sprintf("0/%d remaining nodes are available: %s", len(remaining), strings.Join(summary, ", ")), nil
+}
+
+// fits returns why the pod does not fit on the node or an empty string if it fits
+func (c *capacityNode) fits(pod *corev1.Pod, requests corev1.ResourceList, affinity nodeaffinity.RequiredNodeAffinity, volumes []*corev1.NodeSelector, remaining []*capacityNode) (string, error) {
+
+	if c.node.Spec.Unschedulable {
+		return capacityReasonUnschedulable, nil
+	}
+
+	if !nodeIsReady(c.node) {
+		return capacityReasonNotReady, nil
+	}
+
+	_, untolerated := corev1helpers.FindMatchingUntoleratedTaint(c.node.Spec.Taints, pod.Spec.Tolerations, func(t *corev1.Taint) bool {
+		return t.Effect == corev1.TaintEffectNoSchedule || t.Effect == corev1.TaintEffectNoExecute
+	})
+
+	if untolerated {
+		return capacityReasonTaint, nil
+	}
+
+	if ok, err := affinity.Match(c.node); err != nil || !ok {
+		return capacityReasonAffinity, err
+	}
+
+	for _, selector := range volumes {
+		if ok, err := nodeaffinity.NewLazyErrorNodeSelector(selector).Match(c.node); err != nil || !ok {
+			return capacityReasonVolume, err
+		}
+	}
+
+	if reason := c.insufficient(requests); reason != "" {
+		return reason, nil
+	}
+
+	if !spreadSatisfied(pod, c.node, remaining) {
+		return capacityReasonSpread, nil
+	}
+
+	return "", nil
+}
+
+func (c *capacityNode) add(pod *corev1.Pod) {
+	addResources(c.requested, podRequests(pod))
+	c.pods = append(c.pods, pod)
+}
+
+// insufficient returns the first resource which is not left on the node for the requests of a pod
+func (c *capacityNode) insufficient(requests corev1.ResourceList) string {
+
+	allocatable := c.node.Status.Allocatable
+
+	if max, ok := allocatable[corev1.ResourcePods]; ok && int64(len(c.pods)+1) > max.Value() {
+		return capacityReasonTooManyPods
+	}
+
+	names := []string{}
+
+	for name := range requests {
+		names = append(names, string(name))
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		request := requests[corev1.ResourceName(name)]
+
+		if request.IsZero() {
+			continue
+		}
+
+		free := allocatable[corev1.ResourceName(name)].DeepCopy()
+		free.Sub(c.requested[corev1.ResourceName(name)])
+
+		if free.Cmp(request) < 0 {
+			return capacityReasonInsufficient + name
+		}
+	}
+
+	return ""
+}
+
+// volumeAffinity returns the node selectors of the persistent volumes bound to claims of the pod
+func (q *Quarantine) volumeAffinity(pod *corev1.Pod) ([]*corev1.NodeSelector, error) {
+
+	selectors := []*corev1.NodeSelector{}
+
+	for _, v := range pod.Spec.Volumes {
+
+		if v.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		pvc, err := q.Client.CoreV1().PersistentVolumeClaims(pod.ObjectMeta.Namespace).Get(context.TODO(), v.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+
+		if err != nil {
+			return nil, err
+		}
+
+		// unbound claims are provisioned in the topology of the node the pod is scheduled on
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+
+		pv, err := q.Client.CoreV1().PersistentVolumes().Get(context.TODO(), pvc.Spec.VolumeName, metav1.GetOptions{})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+			selectors = append(selectors, pv.Spec.NodeAffinity.Required)
+			continue
+		}
+
+		for _, label := range volumeZoneLabels {
+			if zone, ok := pv.ObjectMeta.Labels[label]; ok {
+				selectors = append(selectors, &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: label, Operator: corev1.NodeSelectorOpIn, Values: []string{zone}},
+						}},
+					},
+				})
+			}
+		}
+	}
+
+	return selectors, nil
+}
+
+// spreadSatisfied returns if the pod placed on the node keeps the skew of its hard topology spread constraints
+func spreadSatisfied(pod *corev1.Pod, node *corev1.Node, remaining []*capacityNode) bool {
+
+	for _, constraint := range pod.Spec.TopologySpreadConstraints {
+
+		if constraint.WhenUnsatisfiable != corev1.DoNotSchedule {
+			continue
+		}
+
+		value, ok := node.ObjectMeta.Labels[constraint.TopologyKey]
+
+		if !ok {
+			return false
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+
+		if err != nil {
+			return false
+		}
+
+		counts := map[string]int{}
+
+		for _, c := range remaining {
+
+			domain, ok := c.node.ObjectMeta.Labels[constraint.TopologyKey]
+
+			if !ok {
+				continue
+			}
+
+			counts[domain] += 0
+
+			for _, p := range c.pods {
+				if p.ObjectMeta.Namespace == pod.ObjectMeta.Namespace && selector.Matches(labels.Set(p.ObjectMeta.Labels)) {
+					counts[domain]++
+				}
+			}
+		}
+
+		min := counts[value]
+
+		for _, count := range counts {
+			if count < min {
+				min = count
+			}
+		}
+
+		if int32(counts[value]+1-min) > constraint.MaxSkew {
+			return false
+		}
+	}
+
+	return true
+}
+
+// podRequests returns the resources the scheduler reserves for the pod
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+
+	requests := corev1.ResourceList{}
+
+	for _, c := range pod.Spec.Containers {
+		addResources(requests, c.Resources.Requests)
+	}
+
+	// init containers run one after another so only the largest request counts
+	for _, c := range pod.Spec.InitContainers {
+		for name, quantity := range c.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+
+	addResources(requests, pod.Spec.Overhead)
+
+	return requests
+}
+
+func addResources(list, add corev1.ResourceList) {
+
+	for name, quantity := range add {
+		current := list[name].DeepCopy()
+		current.Add(quantity)
+		list[name] = current
+	}
+}
+
+func podTerminated(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// nodeIsReady returns if the ready condition of the node is true. Nodes without ready condition are handled as ready.
+func nodeIsReady(node *corev1.Node) bool {
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,329 @@
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+)
+
+const comparePoolLabel = "node.kubernetes.io/instance-type"
+const compareMaxDifferences = 25
+const compareTimeout = 30 * time.Second
+
+type compareCollector struct {
+	category string
+	command  string
+	parse    func(string) map[string]string
+}
+
+// every collector runs inside of the debug pod which shares the network of the host and has the host filesystem mounted under /host
+var compareCollectors = []compareCollector{
+	{
+		category: "kernel",
+		command:  "uname -r",
+		parse: func(out string) map[string]string {
+			return map[string]string{"release": strings.TrimSpace(out)}
+		},
+	},
+	{
+		category: "sysctl",
+		command:  "sysctl -a",
+		parse:    parseKeyValues(" = "),
+	},
+	{
+		category: "modules",
+		command:  "cut -d' ' -f1 /proc/modules",
+		parse:    parseLines,
+	},
+	{
+		category: "containerd",
+		command:  "grep -v '^[[:space:]]*#' /host/etc/containerd/config.toml | grep -v '^[[:space:]]*$'",
+		parse:    parseLines,
+	},
+	{
+		category: "kubelet",
+		command:  "grep -v '^[[:space:]]*#' /host/var/lib/kubelet/config.yaml | grep -v '^[[:space:]]*$'",
+		parse:    parseLines,
+	},
+	{
+		// rules are counted per table, a failing iptables-save must not look like a node without rules
+		category: "iptables",
+		command:  "rules=$(iptables-save) || exit 1; echo \"$rules\" | awk '/^\\*/{t=substr($1,2)} /^-A/{c[t]++} END{for(k in c) print k, c[k]}'",
+		parse:    parseKeyValues(" "),
+	},
+	{
+		category: "mounts",
+		command:  "awk '{print $2, $3}' /host/proc/1/mounts",
+		parse:    parseKeyValues(" "),
+	},
+}
+
+// keys which differ on every node and are therefore not relevant for a comparison
+var compareIgnoredKeys = []string{
+	"kernel.random.",
+	"kernel.hostname",
+	"kernel.ns_last_pid",
+	"kernel.pty.nr",
+	"fs.dentry-state",
+	"fs.file-nr",
+	"fs.inode-",
+	"fs.quota.",
+	"net.netfilter.nf_conntrack_count",
+	"/var/lib/kubelet/pods/",
+	"/run/containerd/",
+	"/run/netns/",
+}
+
+func (q *Quarantine) compareNode(n *Node) v1alpha1.NodeComparison {
+
+	result := v1alpha1.NodeComparison{
+		Node:        n.Name,
+		Differences: []v1alpha1.NodeDifference{},
+		Truncated:   []string{},
+		Errors:      []string{},
+	}
+
+	reference, err := q.getReferenceNode(n)
+
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+
+	result.ReferenceNode = reference
+
+	n.Logger.Info("collecting node state...", "reference", reference)
+
+	// the debug pods on both nodes are started together so that waiting for them is limited by a single timeout
+	running := map[string]bool{}
+
+	for _, name := range []string{n.Name, reference} {
+		if err := q.Debug.deploy(q.Client, name, q.Audit); err != nil {
+			result.Errors = append(result.Errors, name+": "+err.Error())
+			continue
+		}
+
+		running[name] = true
+	}
+
+	if !(q.Debug.Enabled || n.Debug.Enabled) && running[n.Name] {
+		defer q.Debug.remove(q.Client, n.Name, q.Audit, n.Logger)
+	}
+
+	if running[reference] {
+		defer q.Debug.remove(q.Client, reference, q.Audit, n.Logger)
+	}
+
+	for _, name := range waitForDebugPods(q.Client, q.Debug.Namespace, running, q.Compare.Timeout) {
+		result.Errors = append(result.Errors, name+": debug pod not running")
+		delete(running, name)
+	}
+
+	if !running[n.Name] || !running[reference] {
+		return result
+	}
+
+	nodeState, nodeErrors := q.collectNodeState(n, n.Name)
+	referenceState, referenceErrors := q.collectNodeState(n, reference)
+
+	result.Errors = append(result.Errors, nodeErrors...)
+	result.Errors = append(result.Errors, referenceErrors...)
+
+	for _, collector := range compareCollectors {
+		differences, truncated := diffNodeState(collector.category, nodeState[collector.category], referenceState[collector.category])
+		result.Differences = append(result.Differences, differences...)
+
+		if truncated {
+			result.Truncated = append(result.Truncated, collector.category)
+		}
+	}
+
+	return result
+}
+
+func (q *Quarantine) collectNodeState(n *Node, nodeName string) (map[string]map[string]string, []string) {
+
+	state := map[string]map[string]string{}
+	collectErrors := []string{}
+	podName := debugPodName + "-" + nodeName
+
+	exec := q.Exec
+
+	if exec == nil {
+		config, err := n.factory.ToRESTConfig()
+
+		if err != nil {
+			return state, append(collectErrors, nodeName+": "+err.Error())
+		}
+
+		exec = func(namespace, name, container string, command []string) (string, error) {
+			return execInPod(config, q.Client, namespace, name, container, command)
+		}
+	}
+
+	for _, collector := range compareCollectors {
+
+		out, err := exec(q.Debug.Namespace, podName, debugPodContainerName, []string{"sh", "-c", collector.command})
+
+		if err != nil {
+			collectErrors = append(collectErrors, nodeName+": "+collector.category+": "+err.Error())
+			continue
+		}
+
+		state[collector.category] = collector.parse(out)
+	}
+
+	return state, collectErrors
+}
+
+func (q *Quarantine) getReferenceNode(n *Node) (string, error) {
+
+	if q.Compare.ReferenceNode != "" {
+		return q.Compare.ReferenceNode, nil
+	}
+
+	var nodes *corev1.NodeList
+	var err error
+
+	nodeObj := n.getNodeAPIObject()
+
+	if nodeObj == nil {
+		return "", errors.New("node " + n.Name + " not found")
+	}
+
+	pool, ok := nodeObj.ObjectMeta.Labels[q.Compare.PoolLabel]
+
+	if !ok {
+		return "", errors.New("node " + n.Name + " has no label " + q.Compare.PoolLabel)
+	}
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: q.Compare.PoolLabel + "=" + pool,
+	}
+
+	if nodes, err = q.Client.CoreV1().Nodes().List(context.TODO(), listOpts); err != nil {
+		return "", err
+	}
+
+	for _, candidate := range nodes.Items {
+		if !q.isQuarantinedNode(candidate.ObjectMeta.Name) && isHealthyNode(candidate) {
+			return candidate.ObjectMeta.Name, nil
+		}
+	}
+
+	return "", errors.New("no healthy reference node found in pool " + pool)
+}
+
+func (q *Quarantine) isQuarantinedNode(name string) bool {
+
+	for _, n := range q.Nodes {
+		if n.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isHealthyNode(node corev1.Node) bool {
+
+	if node.Spec.Unschedulable {
+		return false
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// diffNodeState returns the differences of a category and if more than the listed differences were found
+func diffNodeState(category string, nodeValues, referenceValues map[string]string) ([]v1alpha1.NodeDifference, bool) {
+
+	keys := []string{}
+	seen := map[string]bool{}
+
+	for _, values := range []map[string]string{nodeValues, referenceValues} {
+		for k := range values {
+			if !seen[k] && !isIgnoredCompareKey(k) {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	differences := []v1alpha1.NodeDifference{}
+
+	for _, k := range keys {
+		if nodeValues[k] == referenceValues[k] {
+			continue
+		}
+
+		if len(differences) == compareMaxDifferences {
+			return differences, true
+		}
+
+		differences = append(differences, v1alpha1.NodeDifference{
+			Category:  category,
+			Key:       k,
+			Node:      nodeValues[k],
+			Reference: referenceValues[k],
+		})
+	}
+
+	return differences, false
+}
+
+func isIgnoredCompareKey(key string) bool {
+
+	for _, ignored := range compareIgnoredKeys {
+		if strings.Contains(key, ignored) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseLines(out string) map[string]string {
+
+	values := map[string]string{}
+
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			values[line] = "present"
+		}
+	}
+
+	return values
+}
+
+func parseKeyValues(sep string) func(string) map[string]string {
+	return func(out string) map[string]string {
+
+		values := map[string]string{}
+
+		for _, line := range strings.Split(out, "\n") {
+			parts := strings.SplitN(strings.TrimSpace(line), sep, 2)
+
+			if len(parts) != 2 {
+				continue
+			}
+
+			values[parts[0]] = parts[1]
+		}
+
+		return values
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-logr/logr"
 
+	"github.com/soer3n/incident-operator/internal/audit"
+
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,11 +18,15 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-const (
-	rescheduleStrategy = "evict"
-)
+func (ds Daemonset) manageWorkload(c kubernetes.Interface, node string, isolatedNode bool, plan *Plan, events *Events, trail *audit.Trail, logger logr.Logger) error {
 
-func (ds Daemonset) manageWorkload(c kubernetes.Interface, node string, isolatedNode bool, logger logr.Logger) error {
+	switch ds.Strategy {
+	case strategyEvict, strategyDelete:
+		// pods are removed from the node together with unlisted pods
+		return nil
+	case strategyKeep:
+		return ds.tolerate(c, node, plan, events, trail, logger)
+	}
 
 	if ds.Keep {
 
@@ -41,7 +47,7 @@ func (ds Daemonset) manageWorkload(c kubernetes.Interface, node string, isolated
 			return err
 		}
 
-		if err := ds.isolatePod(c, node, isolatedNode, logger); err != nil {
+		if err := ds.isolatePod(c, node, isolatedNode, plan, events, trail, logger); err != nil {
 			return err
 		}
 	}
@@ -49,10 +55,9 @@ func (ds Daemonset) manageWorkload(c kubernetes.Interface, node string, isolated
 	return nil
 }
 
-func (ds Daemonset) isolatePod(c kubernetes.Interface, node string, isolatedNode bool, logger logr.Logger) error {
+func (ds Daemonset) isolatePod(c kubernetes.Interface, node string, isolatedNode bool, plan *Plan, events *Events, trail *audit.Trail, logger logr.Logger) error {
 
 	var obj *v1.DaemonSet
-	var patch []byte
 	var err error
 
 	getOpts := metav1.GetOptions{}
@@ -64,53 +69,84 @@ func (ds Daemonset) isolatePod(c kubernetes.Interface, node string, isolatedNode
 
 	podMatchLabels := obj.Spec.Selector.DeepCopy()
 
-	if err = updatePod(c, podMatchLabels.MatchLabels, node, ds.Namespace, true, true); err != nil {
+	if err = updatePod(c, podMatchLabels.MatchLabels, node, ds.Namespace, dsType+"/"+ds.Name, true, true, plan, events, trail); err != nil {
 		return err
 	}
 
 	logger.Info("pod isolated from workload...")
 
 	if ds.Keep {
+		return ds.patchToleration(c, node, obj, plan, events, trail, logger)
+	}
+
+	return nil
+}
+
+// tolerate represents adding the quarantine toleration to the workload without isolating its pod so that it keeps running on the node
+func (ds Daemonset) tolerate(c kubernetes.Interface, node string, plan *Plan, events *Events, trail *audit.Trail, logger logr.Logger) error {
+
+	obj, err := c.AppsV1().DaemonSets(ds.Namespace).Get(context.TODO(), ds.Name, metav1.GetOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	return ds.patchToleration(c, node, obj, plan, events, trail, logger)
+}
+
+func (ds Daemonset) patchToleration(c kubernetes.Interface, node string, obj *v1.DaemonSet, plan *Plan, events *Events, trail *audit.Trail, logger logr.Logger) error {
+
+	var patched *v1.DaemonSet
+	var patch []byte
+	var err error
+
+	if plan != nil {
+		plan.add(node, planActionToleration, dsType+"/"+ds.Namespace+"/"+ds.Name, quarantineTaintKey+":"+quarantineTaintEffect)
+		return nil
+	}
 
-		patchPayload := []tolerationPayload{
-			{
-				Op:   "add",
-				Path: "/spec/template/spec/tolerations",
-				Value: []tolerationValue{
-					{
-						Key:      quarantineTaintKey,
-						Operator: quarantineTaintOperator,
-						Effect:   quarantineTaintEffect,
-					},
+	patchPayload := []tolerationPayload{
+		{
+			Op:   "add",
+			Path: "/spec/template/spec/tolerations",
+			Value: []tolerationValue{
+				{
+					Key:      quarantineTaintKey,
+					Operator: quarantineTaintOperator,
+					Effect:   quarantineTaintEffect,
 				},
 			},
-		}
-
-		patchOpts := metav1.PatchOptions{}
+		},
+	}
 
-		if patch, err = json.Marshal(patchPayload); err != nil {
-			return err
-		}
+	patchOpts := metav1.PatchOptions{}
 
-		if _, err = c.AppsV1().DaemonSets(ds.Namespace).Patch(context.TODO(), ds.Name, types.JSONPatchType, patch, patchOpts); err != nil {
-			return err
-		}
+	if patch, err = json.Marshal(patchPayload); err != nil {
+		return err
+	}
 
-		logger.Info("modified...")
+	if patched, err = c.AppsV1().DaemonSets(ds.Namespace).Patch(context.TODO(), ds.Name, types.JSONPatchType, patch, patchOpts); err != nil {
+		return err
 	}
 
+	trail.Add(audit.VerbPatch, "DaemonSet", ds.Namespace, ds.Name, obj, patched)
+
+	logger.Info("modified...")
+	events.emit(obj, eventReasonTolerationPatched, "toleration for %s added to %s %s/%s", quarantineTaintKey, dsType, ds.Namespace, ds.Name)
+
 	return nil
 }
 
-func (ds Daemonset) removeToleration(c kubernetes.Interface) error {
+func (ds Daemonset) removeToleration(c kubernetes.Interface, trail *audit.Trail) error {
 
+	var obj, patched *v1.DaemonSet
 	var patch []byte
 	var err error
 
 	getOpts := metav1.GetOptions{}
 
 	// get affected daemonset
-	if _, err = c.AppsV1().DaemonSets(ds.Namespace).Get(context.TODO(), ds.Name, getOpts); err != nil {
+	if obj, err = c.AppsV1().DaemonSets(ds.Namespace).Get(context.TODO(), ds.Name, getOpts); err != nil {
 		return err
 	}
 
@@ -128,10 +164,12 @@ func (ds Daemonset) removeToleration(c kubernetes.Interface) error {
 		return err
 	}
 
-	if _, err = c.AppsV1().DaemonSets(ds.Namespace).Patch(context.TODO(), ds.Name, types.JSONPatchType, patch, patchOpts); err != nil {
+	if patched, err = c.AppsV1().DaemonSets(ds.Namespace).Patch(context.TODO(), ds.Name, types.JSONPatchType, patch, patchOpts); err != nil {
 		return err
 	}
 
+	trail.Add(audit.VerbPatch, "DaemonSet", ds.Namespace, ds.Name, obj, patched)
+
 	return nil
 }
 
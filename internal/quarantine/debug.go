@@ -8,6 +8,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/audit"
 )
 
 const debugPodName = "quarantine-debug"
@@ -15,12 +18,13 @@ const debugPodNamespace = "kube-system"
 const debugPodImage = "nicolaka/netshoot"
 const debugPodContainerName = "debug"
 
-func (dg Debug) deploy(c kubernetes.Interface, nodeName string) error {
+func (dg Debug) deploy(c kubernetes.Interface, nodeName string, trail *audit.Trail) error {
+	var created *corev1.Pod
 	var err error
 
 	getOpts := metav1.GetOptions{}
 
-	if _, err = c.CoreV1().Pods(dg.Namespace).Get(context.TODO(), debugPodName, getOpts); err == nil {
+	if _, err = c.CoreV1().Pods(dg.Namespace).Get(context.TODO(), debugPodName+"-"+nodeName, getOpts); err == nil {
 		return nil
 	}
 
@@ -50,6 +54,12 @@ func (dg Debug) deploy(c kubernetes.Interface, nodeName string) error {
 					Image: debugPodImage,
 					Stdin: true,
 					TTY:   true,
+					// reading the iptables rules of the host network needs these capabilities but no privileged container
+					SecurityContext: &corev1.SecurityContext{
+						Capabilities: &corev1.Capabilities{
+							Add: []corev1.Capability{"NET_ADMIN", "NET_RAW"},
+						},
+					},
 					VolumeMounts: []corev1.VolumeMount{
 						{
 							Name:      "host-system",
@@ -75,20 +85,23 @@ func (dg Debug) deploy(c kubernetes.Interface, nodeName string) error {
 	debugPod.ObjectMeta.Labels[QuarantinePodLabelPrefix+QuarantinePodLabelKey] = quarantinePodLabelValue
 	createOpts := metav1.CreateOptions{}
 
-	if _, err = c.CoreV1().Pods(dg.Namespace).Create(context.TODO(), debugPod, createOpts); err != nil {
+	if created, err = c.CoreV1().Pods(dg.Namespace).Create(context.TODO(), debugPod, createOpts); err != nil {
 		return err
 	}
 
+	trail.Add(audit.VerbCreate, "Pod", dg.Namespace, debugPod.ObjectMeta.Name, nil, created)
+
 	return nil
 }
 
-func (dg Debug) remove(c kubernetes.Interface, nodeName string, logger logr.Logger) {
+func (dg Debug) remove(c kubernetes.Interface, nodeName string, trail *audit.Trail, logger logr.Logger) {
 
+	var pod *corev1.Pod
 	var err error
 
 	getOpts := metav1.GetOptions{}
 
-	if _, err = c.CoreV1().Pods(dg.Namespace).Get(context.TODO(), debugPodName+"-"+nodeName, getOpts); err != nil {
+	if pod, err = c.CoreV1().Pods(dg.Namespace).Get(context.TODO(), debugPodName+"-"+nodeName, getOpts); err != nil {
 		logger.Info("debug pod not found", "node", nodeName)
 		return
 	}
@@ -100,5 +113,19 @@ func (dg Debug) remove(c kubernetes.Interface, nodeName string, logger logr.Logg
 		return
 	}
 
+	trail.Add(audit.VerbDelete, "Pod", dg.Namespace, pod.ObjectMeta.Name, pod, nil)
+
 	logger.Info("debug pod deleted", "node", nodeName)
 }
+
+// DebugPodRef represents namespace, name and container of the debug pod of a quarantine on a node
+func DebugPodRef(s *v1alpha1.Quarantine, nodeName string) (string, string, string) {
+
+	namespace := debugPodNamespace
+
+	if s.Spec.Debug.Namespace != "" {
+		namespace = s.Spec.Debug.Namespace
+	}
+
+	return namespace, debugPodName + "-" + nodeName, debugPodContainerName
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-logr/logr"
 
+	"github.com/soer3n/incident-operator/internal/audit"
+
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,7 +18,15 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-func (d Deployment) manageWorkload(c kubernetes.Interface, node string, isolatedNode bool, logger logr.Logger) error {
+func (d Deployment) manageWorkload(c kubernetes.Interface, node string, isolatedNode bool, plan *Plan, events *Events, trail *audit.Trail, logger logr.Logger) error {
+
+	switch d.Strategy {
+	case strategyEvict, strategyDelete:
+		// pods are removed from the node together with unlisted pods
+		return nil
+	case strategyKeep:
+		return d.tolerate(c, node, plan, events, trail, logger)
+	}
 
 	if d.Keep {
 
@@ -37,7 +47,7 @@ func (d Deployment) manageWorkload(c kubernetes.Interface, node string, isolated
 			return err
 		}
 
-		if err := d.isolatePod(c, node, isolatedNode, logger); err != nil {
+		if err := d.isolatePod(c, node, isolatedNode, plan, events, trail, logger); err != nil {
 			return err
 		}
 	}
@@ -45,10 +55,9 @@ func (d Deployment) manageWorkload(c kubernetes.Interface, node string, isolated
 	return nil
 }
 
-func (d Deployment) isolatePod(c kubernetes.Interface, node string, isolatedNode bool, logger logr.Logger) error {
+func (d Deployment) isolatePod(c kubernetes.Interface, node string, isolatedNode bool, plan *Plan, events *Events, trail *audit.Trail, logger logr.Logger) error {
 
 	var obj *v1.Deployment
-	var patch []byte
 	var err error
 
 	opts := metav1.GetOptions{}
@@ -57,53 +66,84 @@ func (d Deployment) isolatePod(c kubernetes.Interface, node string, isolatedNode
 		return err
 	}
 
-	if err := updatePod(c, obj.Spec.Selector.MatchLabels, node, d.Namespace, true, true); err != nil {
+	if err := updatePod(c, obj.Spec.Selector.MatchLabels, node, d.Namespace, deploymentType+"/"+d.Name, true, true, plan, events, trail); err != nil {
 		return err
 	}
 
 	logger.Info("pod isolated from workload...")
 
 	if d.Keep {
-		patchPayload := []tolerationPayload{
-			{
-				Op:   "add",
-				Path: "/spec/template/spec/tolerations",
-				Value: []tolerationValue{
-					{
-						Key:      quarantineTaintKey,
-						Operator: quarantineTaintOperator,
-						Effect:   quarantineTaintEffect,
-					},
+		return d.patchToleration(c, node, obj, plan, events, trail, logger)
+	}
+
+	return nil
+}
+
+// tolerate represents adding the quarantine toleration to the workload without isolating its pod so that it keeps running on the node
+func (d Deployment) tolerate(c kubernetes.Interface, node string, plan *Plan, events *Events, trail *audit.Trail, logger logr.Logger) error {
+
+	obj, err := c.AppsV1().Deployments(d.Namespace).Get(context.TODO(), d.Name, metav1.GetOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	return d.patchToleration(c, node, obj, plan, events, trail, logger)
+}
+
+func (d Deployment) patchToleration(c kubernetes.Interface, node string, obj *v1.Deployment, plan *Plan, events *Events, trail *audit.Trail, logger logr.Logger) error {
+
+	var patched *v1.Deployment
+	var patch []byte
+	var err error
+
+	if plan != nil {
+		plan.add(node, planActionToleration, deploymentType+"/"+d.Namespace+"/"+d.Name, quarantineTaintKey+":"+quarantineTaintEffect)
+		return nil
+	}
+
+	patchPayload := []tolerationPayload{
+		{
+			Op:   "add",
+			Path: "/spec/template/spec/tolerations",
+			Value: []tolerationValue{
+				{
+					Key:      quarantineTaintKey,
+					Operator: quarantineTaintOperator,
+					Effect:   quarantineTaintEffect,
 				},
 			},
-		}
+		},
+	}
 
-		patchOpts := metav1.PatchOptions{}
+	patchOpts := metav1.PatchOptions{}
 
-		if patch, err = json.Marshal(patchPayload); err != nil {
-			return err
-		}
+	if patch, err = json.Marshal(patchPayload); err != nil {
+		return err
+	}
 
-		if _, err = c.AppsV1().Deployments(d.Namespace).Patch(context.TODO(), d.Name, types.JSONPatchType, patch, patchOpts); err != nil {
-			return err
-		}
+	if patched, err = c.AppsV1().Deployments(d.Namespace).Patch(context.TODO(), d.Name, types.JSONPatchType, patch, patchOpts); err != nil {
+		return err
+	}
 
-		logger.Info("modified...")
+	trail.Add(audit.VerbPatch, "Deployment", d.Namespace, d.Name, obj, patched)
 
-	}
+	logger.Info("modified...")
+	events.emit(obj, eventReasonTolerationPatched, "toleration for %s added to %s %s/%s", quarantineTaintKey, deploymentType, d.Namespace, d.Name)
 
 	return nil
 }
 
-func (d Deployment) removeToleration(c kubernetes.Interface) error {
+func (d Deployment) removeToleration(c kubernetes.Interface, trail *audit.Trail) error {
 
+	var obj, patched *v1.Deployment
 	var patch []byte
 	var err error
 
 	getOpts := metav1.GetOptions{}
 
 	// get affected daemonset
-	if _, err = c.AppsV1().Deployments(d.Namespace).Get(context.TODO(), d.Name, getOpts); err != nil {
+	if obj, err = c.AppsV1().Deployments(d.Namespace).Get(context.TODO(), d.Name, getOpts); err != nil {
 		return err
 	}
 
@@ -121,10 +161,12 @@ func (d Deployment) removeToleration(c kubernetes.Interface) error {
 		return err
 	}
 
-	if _, err = c.AppsV1().Deployments(d.Namespace).Patch(context.TODO(), d.Name, types.JSONPatchType, patch, patchOpts); err != nil {
+	if patched, err = c.AppsV1().Deployments(d.Namespace).Patch(context.TODO(), d.Name, types.JSONPatchType, patch, patchOpts); err != nil {
 		return err
 	}
 
+	trail.Add(audit.VerbPatch, "Deployment", d.Namespace, d.Name, obj, patched)
+
 	patchLabelPayload := []labelPayload{
 		{
 			Op:   "add",
@@ -139,10 +181,12 @@ func (d Deployment) removeToleration(c kubernetes.Interface) error {
 		return err
 	}
 
-	if _, err = c.AppsV1().Deployments(d.Namespace).Patch(context.TODO(), d.Name, types.JSONPatchType, patch, patchOpts); err != nil {
+	if patched, err = c.AppsV1().Deployments(d.Namespace).Patch(context.TODO(), d.Name, types.JSONPatchType, patch, patchOpts); err != nil {
 		return err
 	}
 
+	trail.Add(audit.VerbPatch, "Deployment", d.Namespace, d.Name, obj, patched)
+
 	return nil
 }
 
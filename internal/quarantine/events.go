@@ -0,0 +1,50 @@
+package quarantine
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const eventReasonCordoned = "Cordoned"
+const eventReasonTainted = "Tainted"
+const eventReasonPodIsolated = "PodIsolated"
+const eventReasonTolerationPatched = "TolerationPatched"
+const eventReasonEvicted = "Evicted"
+const eventReasonDeleted = "Deleted"
+const eventReasonDrained = "Drained"
+const eventReasonDebugPodCreated = "DebugPodCreated"
+const eventReasonReleased = "Released"
+const eventReasonOutOfService = "OutOfService"
+const eventReasonEvictionBlocked = "EvictionBlocked"
+
+// Events represents emitting kubernetes events for actions of a quarantine on the resource and the affected objects
+type Events struct {
+	Recorder record.EventRecorder
+	object   runtime.Object
+}
+
+// emit records an event on the quarantine resource and on the related object if it is set
+func (e *Events) emit(related runtime.Object, reason, messageFmt string, args ...interface{}) {
+	e.record(corev1.EventTypeNormal, related, reason, messageFmt, args...)
+}
+
+// warn records a warning on the quarantine resource and on the related object if it is set
+func (e *Events) warn(related runtime.Object, reason, messageFmt string, args ...interface{}) {
+	e.record(corev1.EventTypeWarning, related, reason, messageFmt, args...)
+}
+
+func (e *Events) record(eventType string, related runtime.Object, reason, messageFmt string, args ...interface{}) {
+
+	if e == nil || e.Recorder == nil {
+		return
+	}
+
+	if e.object != nil {
+		e.Recorder.Eventf(e.object, eventType, reason, messageFmt, args...)
+	}
+
+	if related != nil {
+		e.Recorder.Eventf(related, eventType, reason, messageFmt, args...)
+	}
+}
@@ -0,0 +1,263 @@
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/audit"
+	"github.com/soer3n/incident-operator/internal/metrics"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+const evictionTimeout = 2 * time.Minute
+const evictionBackoffInitial = time.Second
+const evictionBackoffCap = 30 * time.Second
+
+// evict represents evicting pods from the node by the eviction api of the cluster.
+// Evictions rejected by a disruption budget are retried with backoff until the drain timeout while the other pods are evicted.
+// Pods which could not be evicted are recorded as blocked so that the other nodes are handled before the quarantine fails.
+func (n *Node) evict(pods []corev1.Pod) error {
+
+	if len(pods) == 0 {
+		return nil
+	}
+
+	policyGroupVersion := ""
+
+	if !n.Flags.DisableEviction {
+
+		var err error
+
+		if policyGroupVersion, err = utils.SupportEviction(n.Flags.Client.Discovery()); err != nil {
+			return err
+		}
+	}
+
+	timeout := n.evictionTimeout()
+	deadline := time.Now().Add(timeout)
+	backoff := wait.Backoff{
+		Duration: evictionBackoffInitial,
+		Factor:   2,
+		Steps:    math.MaxInt32,
+		Cap:      evictionBackoffCap,
+	}
+
+	pending := pods
+	rejections := map[string]error{}
+
+	for {
+
+		rejected := []corev1.Pod{}
+
+		for _, pod := range pending {
+
+			err := n.evictPod(pod, policyGroupVersion)
+
+			switch {
+			case err == nil:
+				n.events.emit(&pod, eventReasonEvicted, "pod %s/%s evicted from node %s", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, n.Name)
+			case apierrors.IsTooManyRequests(err):
+				rejected = append(rejected, pod)
+				rejections[podTarget(pod)] = err
+			default:
+				metrics.EvictionFailed(err)
+				n.block(pod, "", err.Error())
+			}
+		}
+
+		pending = rejected
+		delay := backoff.Step()
+
+		if len(pending) == 0 || time.Now().Add(delay).After(deadline) {
+			break
+		}
+
+		n.Logger.Info("eviction rejected, retrying...", "pods", len(pending), "delay", delay.String())
+		time.Sleep(delay)
+	}
+
+	for _, pod := range pending {
+
+		err := rejections[podTarget(pod)]
+		metrics.EvictionFailed(err)
+
+		budgets, pdbErr := disruptionBudgets(n.Flags.Client, pod)
+
+		if pdbErr != nil || len(budgets) == 0 {
+			n.block(pod, "", err.Error())
+			continue
+		}
+
+		for _, pdb := range budgets {
+			n.block(pod, pdb.ObjectMeta.Namespace+"/"+pdb.ObjectMeta.Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// evictionTimeout returns the drain timeout of the node or the default if the drain waits infinitely
+func (n *Node) evictionTimeout() time.Duration {
+
+	if n.Flags.Timeout > 0 {
+		return n.Flags.Timeout
+	}
+
+	return evictionTimeout
+}
+
+// waitForDeletion represents waiting until the evicted pods are gone like the drain does. Pods whose deletion timestamp
+// is older than skipWaitForDeleteTimeoutSeconds are not waited for. Pods still terminating after the timeout are logged
+// since they do not block the node from being isolated.
+func (n *Node) waitForDeletion(pods []corev1.Pod) {
+
+	pending := []corev1.Pod{}
+
+	for _, pod := range pods {
+		if !n.hasBlocked(pod) {
+			pending = append(pending, pod)
+		}
+	}
+
+	skipWait := time.Duration(n.Flags.SkipWaitForDeleteTimeoutSeconds) * time.Second
+
+	err := wait.PollImmediate(evictionBackoffInitial, n.evictionTimeout(), func() (bool, error) {
+
+		terminating := []corev1.Pod{}
+
+		for _, pod := range pending {
+
+			current, err := n.Flags.Client.CoreV1().Pods(pod.ObjectMeta.Namespace).Get(context.TODO(), pod.ObjectMeta.Name, metav1.GetOptions{})
+
+			if apierrors.IsNotFound(err) || (err == nil && current.ObjectMeta.UID != pod.ObjectMeta.UID) {
+				continue
+			}
+
+			if err == nil && skipWait > 0 && current.ObjectMeta.DeletionTimestamp != nil && time.Since(current.ObjectMeta.DeletionTimestamp.Time) > skipWait {
+				continue
+			}
+
+			terminating = append(terminating, pod)
+		}
+
+		pending = terminating
+		return len(pending) == 0, nil
+	})
+
+	if err != nil {
+		n.Logger.Info("evicted pods are still terminating", "pods", len(pending))
+	}
+}
+
+// blockedPodsError returns an error if pods of any node could not be evicted
+func (q *Quarantine) blockedPodsError() error {
+
+	pods := map[string]bool{}
+	nodes := []string{}
+
+	for _, n := range q.Nodes {
+
+		if len(n.blocked) == 0 {
+			continue
+		}
+
+		nodes = append(nodes, n.Name)
+
+		for _, b := range n.blocked {
+			pods[b.Pod] = true
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	return errors.New(strconv.Itoa(len(pods)) + " pods could not be evicted from nodes " + strings.Join(nodes, ", ") + ", see status.blockedPods")
+}
+
+// evictPod deletes the pod if the cluster does not support evictions. A pod which is already gone counts as evicted.
+func (n *Node) evictPod(pod corev1.Pod, policyGroupVersion string) error {
+
+	var err error
+	verb := audit.VerbEvict
+
+	if policyGroupVersion == "" {
+		verb = audit.VerbDelete
+		err = n.Flags.DeletePod(pod)
+	} else {
+		err = n.Flags.EvictPod(pod, policyGroupVersion)
+	}
+
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	n.audit.Add(verb, "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, &pod, nil)
+	n.removed.add(&pod)
+	return nil
+}
+
+// hasBlocked returns if the eviction of the pod was already blocked during the current update of the node
+func (n *Node) hasBlocked(pod corev1.Pod) bool {
+
+	for _, b := range n.blocked {
+		if b.Pod == podTarget(pod) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *Node) block(pod corev1.Pod, budget, reason string) {
+	n.blocked = append(n.blocked, v1alpha1.BlockedPod{
+		Node:             n.Name,
+		Pod:              pod.ObjectMeta.Namespace + "/" + pod.ObjectMeta.Name,
+		DisruptionBudget: budget,
+		Reason:           reason,
+	})
+
+	n.events.warn(&pod, eventReasonEvictionBlocked, "pod %s/%s could not be evicted from node %s: %s", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, n.Name, reason)
+}
+
+// disruptionBudgets returns the disruption budgets whose selector matches the pod
+func disruptionBudgets(c kubernetes.Interface, pod corev1.Pod) ([]policyv1beta1.PodDisruptionBudget, error) {
+
+	pdbs, err := c.PolicyV1beta1().PodDisruptionBudgets(pod.ObjectMeta.Namespace).List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	matching := []policyv1beta1.PodDisruptionBudget{}
+
+	for _, pdb := range pdbs.Items {
+
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.ObjectMeta.Labels)) {
+			continue
+		}
+
+		matching = append(matching, pdb)
+	}
+
+	return matching, nil
+}
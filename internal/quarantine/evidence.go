@@ -0,0 +1,129 @@
+package quarantine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/soer3n/incident-operator/internal/evidence"
+)
+
+const evidenceConfigMapPrefix = "quarantine-evidence-"
+const evidenceSigningKey = "signing.key"
+
+// recordEvidence appends all pending artifacts to the manifest of the quarantine and adds the signed manifest as artifact
+func (q *Quarantine) recordEvidence() error {
+
+	var raw []byte
+	var err error
+
+	cm, manifest, err := q.getEvidenceManifest()
+
+	if err != nil {
+		return err
+	}
+
+	for _, a := range q.pendingArtifacts {
+		entry := manifest.Append(a.Name, a.Content, time.Now())
+		q.Logger.Info("evidence recorded", "artifact", a.Name, "sha256", entry.SHA256)
+	}
+
+	if raw, err = json.MarshalIndent(manifest, "", "  "); err != nil {
+		return err
+	}
+
+	cm.Data[evidence.ManifestFile] = string(raw)
+	q.addArtifact(evidence.ManifestFile, raw)
+
+	if q.Evidence.SigningKeySecret != "" {
+
+		signature, err := q.signManifest(raw)
+
+		if err != nil {
+			return err
+		}
+
+		cm.Data[evidence.SignatureFile] = string(signature)
+		q.addArtifact(evidence.SignatureFile, signature)
+	}
+
+	if cm.ObjectMeta.ResourceVersion == "" {
+		_, err = q.Client.CoreV1().ConfigMaps(q.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+		return err
+	}
+
+	_, err = q.Client.CoreV1().ConfigMaps(q.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	return err
+}
+
+func (q *Quarantine) getEvidenceManifest() (*corev1.ConfigMap, *evidence.Manifest, error) {
+
+	manifest := &evidence.Manifest{
+		Quarantine: q.Namespace + "/" + q.Name,
+		Entries:    []evidence.Entry{},
+	}
+
+	cm, err := q.Client.CoreV1().ConfigMaps(q.Namespace).Get(context.TODO(), evidenceConfigMapPrefix+q.Name, metav1.GetOptions{})
+
+	if k8serrors.IsNotFound(err) {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      evidenceConfigMapPrefix + q.Name,
+				Namespace: q.Namespace,
+				Labels: map[string]string{
+					QuarantinePodLabelPrefix + quarantineNameLabelKey: q.Name,
+				},
+			},
+			Data: map[string]string{},
+		}, manifest, nil
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+
+	if raw, ok := cm.Data[evidence.ManifestFile]; ok {
+		if err := json.Unmarshal([]byte(raw), manifest); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// never append to a manifest which was tampered with
+	if err := manifest.Verify(); err != nil {
+		return nil, nil, err
+	}
+
+	return cm, manifest, nil
+}
+
+func (q *Quarantine) signManifest(raw []byte) ([]byte, error) {
+
+	secret, err := q.Client.CoreV1().Secrets(q.Namespace).Get(context.TODO(), q.Evidence.SigningKeySecret, metav1.GetOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := secret.Data[evidenceSigningKey]
+
+	if !ok {
+		return nil, errors.New("secret " + q.Evidence.SigningKeySecret + " has no key " + evidenceSigningKey)
+	}
+
+	key, err := evidence.ParsePrivateKey(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return evidence.Sign(raw, key), nil
+}
@@ -0,0 +1,90 @@
+package quarantine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const podRunningInterval = 2 * time.Second
+
+// ExecFunc represents running a command in a container of a pod and returning its output
+type ExecFunc func(namespace, name, container string, command []string) (string, error)
+
+func execInPod(config *rest.Config, c kubernetes.Interface, namespace, name, container string, command []string) (string, error) {
+
+	var stdout, stderr bytes.Buffer
+
+	req := c.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(name).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+
+	if err != nil {
+		return "", err
+	}
+
+	if err = executor.Stream(remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+
+	return stdout.String(), nil
+}
+
+// waitForDebugPods waits until the debug pods of the nodes are running and returns the nodes whose debug pod did not start in time
+func waitForDebugPods(c kubernetes.Interface, namespace string, nodes map[string]bool, timeout time.Duration) []string {
+
+	pending := []string{}
+
+	for name := range nodes {
+		pending = append(pending, name)
+	}
+
+	sort.Strings(pending)
+
+	_ = wait.PollImmediate(podRunningInterval, timeout, func() (bool, error) {
+
+		waiting := []string{}
+
+		for _, name := range pending {
+
+			pod, err := c.CoreV1().Pods(namespace).Get(context.TODO(), debugPodName+"-"+name, metav1.GetOptions{})
+
+			if err != nil || pod.Status.Phase != corev1.PodRunning {
+				waiting = append(waiting, name)
+			}
+		}
+
+		pending = waiting
+
+		return len(pending) == 0, nil
+	})
+
+	return pending
+}
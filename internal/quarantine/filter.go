@@ -0,0 +1,98 @@
+package quarantine
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubectl/pkg/drain"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+// PodFilter represents pods which are kept on a node by the drain and the eviction of an isolated node
+type PodFilter struct {
+	Namespaces       []string
+	PriorityClasses  []string
+	Selectors        []labels.Selector
+	SkipLocalStorage bool
+}
+
+// parseFilters merges the filters of the node into the global filters. Lists are joined, the node overrides flags.
+func (n *Node) parseFilters(baseFilters, nodeFilters v1alpha1.Filters) error {
+
+	filter := PodFilter{
+		Namespaces:      append(append([]string{}, baseFilters.ExcludedNamespaces...), nodeFilters.ExcludedNamespaces...),
+		PriorityClasses: append(append([]string{}, baseFilters.ExcludedPriorityClasses...), nodeFilters.ExcludedPriorityClasses...),
+		Selectors:       []labels.Selector{},
+	}
+
+	for _, s := range append(append([]metav1.LabelSelector{}, baseFilters.ExcludedSelectors...), nodeFilters.ExcludedSelectors...) {
+
+		s := s
+		selector, err := metav1.LabelSelectorAsSelector(&s)
+
+		if err != nil {
+			return err
+		}
+
+		filter.Selectors = append(filter.Selectors, selector)
+	}
+
+	if baseFilters.SkipLocalStorage != nil {
+		filter.SkipLocalStorage = *baseFilters.SkipLocalStorage
+	}
+
+	if nodeFilters.SkipLocalStorage != nil {
+		filter.SkipLocalStorage = *nodeFilters.SkipLocalStorage
+	}
+
+	n.Filter = filter
+	n.Flags.AdditionalFilters = []drain.PodFilter{n.Filter.drainFilter}
+
+	return nil
+}
+
+// Excludes represents checking if a pod is kept on the node. The reason is returned for excluded pods.
+func (f PodFilter) Excludes(pod corev1.Pod) (bool, string) {
+
+	if utils.Contains(f.Namespaces, pod.ObjectMeta.Namespace) {
+		return true, "namespace " + pod.ObjectMeta.Namespace + " is excluded"
+	}
+
+	if utils.Contains(f.PriorityClasses, pod.Spec.PriorityClassName) {
+		return true, "priority class " + pod.Spec.PriorityClassName + " is excluded"
+	}
+
+	for _, selector := range f.Selectors {
+		if !selector.Empty() && selector.Matches(labels.Set(pod.ObjectMeta.Labels)) {
+			return true, "labels match excluded selector " + selector.String()
+		}
+	}
+
+	if f.SkipLocalStorage && hasLocalStorage(pod) {
+		return true, "pod has local storage"
+	}
+
+	return false, ""
+}
+
+func (f PodFilter) drainFilter(pod corev1.Pod) drain.PodDeleteStatus {
+
+	if excluded, _ := f.Excludes(pod); excluded {
+		return drain.MakePodDeleteStatusSkip()
+	}
+
+	return drain.MakePodDeleteStatusOkay()
+}
+
+func hasLocalStorage(pod corev1.Pod) bool {
+
+	for _, v := range pod.Spec.Volumes {
+		if v.EmptyDir != nil || v.HostPath != nil {
+			return true
+		}
+	}
+
+	return false
+}
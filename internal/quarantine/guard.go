@@ -0,0 +1,63 @@
+package quarantine
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/safety"
+)
+
+// BlockedReasonSafety represents a quarantine paused because it violates the cluster safety guards
+const BlockedReasonSafety = "safety"
+
+// BlockedReasonCapacity represents a quarantine paused because pods would not fit on the remaining nodes
+const BlockedReasonCapacity = "capacity"
+
+// ReasonDryRun represents a quarantine which was only planned and did not touch its nodes
+const ReasonDryRun = "dryrun"
+
+// BlockedError represents a quarantine which is not allowed to touch its nodes yet
+type BlockedError struct {
+	Reason string
+	Err    error
+}
+
+func (e *BlockedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BlockedError) Unwrap() error {
+	return e.Err
+}
+
+// CheckSafety represents validating the quarantine against the current nodes of the cluster before any node is touched
+func (q *Quarantine) CheckSafety() error {
+
+	nodes, err := q.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	if err := safety.Check(q.spec, nodes.Items); err != nil {
+		return &BlockedError{Reason: BlockedReasonSafety, Err: err}
+	}
+
+	return nil
+}
+
+// IsStarted returns if the quarantine touched its nodes. A quarantine which was paused by a guard or only planned is started again.
+func IsStarted(s *v1alpha1.Quarantine) bool {
+
+	c := meta.FindStatusCondition(s.Status.Conditions, quarantineStatusActiveKey)
+
+	return c != nil && (c.Status == metav1.ConditionTrue || !isBlocked(c.Reason))
+}
+
+// isBlocked returns if the last reconcile did not touch the nodes since it was paused by a guard or only planned
+func isBlocked(reason string) bool {
+	return reason == BlockedReasonSafety || reason == BlockedReasonCapacity || reason == ReasonDryRun
+}
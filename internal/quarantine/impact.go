@@ -0,0 +1,317 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+)
+
+// analyseImpact represents computing which services lose ready endpoints, which disruption budgets drop to zero allowed disruptions
+// and which ingresses have no backends left once the pods of quarantined nodes are relabelled, drained, evicted or deleted.
+func (q *Quarantine) analyseImpact() error {
+
+	pods, err := q.Client.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	affected := map[string]corev1.Pod{}
+
+	for _, n := range q.Nodes {
+
+		list, err := n.affectedPods(pods.Items)
+
+		if err != nil {
+			return err
+		}
+
+		for _, pod := range list {
+			affected[pod.ObjectMeta.Namespace+"/"+pod.ObjectMeta.Name] = pod
+		}
+	}
+
+	impact := &v1alpha1.Impact{}
+	var remaining map[string]int32
+
+	if impact.Services, remaining, err = q.serviceImpact(affected); err != nil {
+		return err
+	}
+
+	if impact.DisruptionBudgets, err = q.disruptionBudgetImpact(affected); err != nil {
+		return err
+	}
+
+	if impact.Ingresses, err = q.ingressImpact(impact.Services, remaining); err != nil {
+		return err
+	}
+
+	q.Impact = impact
+
+	if q.Plan == nil {
+		return nil
+	}
+
+	for _, s := range impact.Services {
+		q.Plan.add(releaseClusterScope, planActionImpact, "service/"+s.Namespace+"/"+s.Name, fmt.Sprintf("%d of %d ready endpoints left", s.RemainingEndpoints, s.Endpoints))
+	}
+
+	for _, b := range impact.DisruptionBudgets {
+		q.Plan.add(releaseClusterScope, planActionImpact, "poddisruptionbudget/"+b.Namespace+"/"+b.Name, fmt.Sprintf("%d pods removed with %d disruptions allowed", b.Pods, b.DisruptionsAllowed))
+	}
+
+	for _, i := range impact.Ingresses {
+		q.Plan.add(releaseClusterScope, planActionImpact, "ingress/"+i.Namespace+"/"+i.Name, "no ready backends left: "+strings.Join(i.Services, ", "))
+	}
+
+	return nil
+}
+
+// affectedPods returns the pods which stop serving on the node because they are relabelled, drained, evicted or deleted
+func (n *Node) affectedPods(pods []corev1.Pod) ([]corev1.Pod, error) {
+
+	workloads, err := n.workloadStrategies()
+
+	if err != nil {
+		return nil, err
+	}
+
+	affected := []corev1.Pod{}
+
+	for _, pod := range pods {
+
+		if pod.Spec.NodeName != n.Name || podTerminated(&pod) || !podIsNotInQuarantine(pod) {
+			continue
+		}
+
+		if _, ok := pod.ObjectMeta.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+			continue
+		}
+
+		if excluded, _ := n.Filter.Excludes(pod); excluded {
+			continue
+		}
+
+		if pod.Spec.PriorityClassName == "system-node-critical" {
+			continue
+		}
+
+		strategy := n.strategyFor(pod, workloads)
+
+		switch {
+		case strategy == strategyKeep:
+			continue
+		// nodes in soft mode only isolate pods of listed workloads
+		case n.soft() && !(strategy == strategyIsolate && selectedByWorkload(pod, workloads)):
+			continue
+		// daemonset pods are not drained without strategy
+		case strategy == "" && isDaemonSetPod(pod):
+			continue
+		}
+
+		affected = append(affected, pod)
+	}
+
+	return affected, nil
+}
+
+// serviceImpact returns the services whose endpoint slices lose ready endpoints together with the ready endpoints left for every service
+func (q *Quarantine) serviceImpact(affected map[string]corev1.Pod) ([]v1alpha1.ServiceImpact, map[string]int32, error) {
+
+	slices, err := q.Client.DiscoveryV1().EndpointSlices("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	services := map[string]*v1alpha1.ServiceImpact{}
+	remaining := map[string]int32{}
+
+	for _, slice := range slices.Items {
+
+		name, ok := slice.ObjectMeta.Labels[discoveryv1.LabelServiceName]
+
+		if !ok {
+			continue
+		}
+
+		key := slice.ObjectMeta.Namespace + "/" + name
+		lost := false
+
+		for _, endpoint := range slice.Endpoints {
+
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+
+			if _, ok := services[key]; !ok {
+				services[key] = &v1alpha1.ServiceImpact{Namespace: slice.ObjectMeta.Namespace, Name: name}
+			}
+
+			services[key].Endpoints++
+
+			if ref := endpoint.TargetRef; ref != nil && ref.Kind == "Pod" {
+				if _, ok := affected[slice.ObjectMeta.Namespace+"/"+ref.Name]; ok {
+					lost = true
+					continue
+				}
+			}
+
+			services[key].RemainingEndpoints++
+		}
+
+		if lost {
+			services[key].EndpointSlices = append(services[key].EndpointSlices, slice.ObjectMeta.Name)
+		}
+	}
+
+	impacts := []v1alpha1.ServiceImpact{}
+
+	for key, s := range services {
+
+		remaining[key] = s.RemainingEndpoints
+
+		if len(s.EndpointSlices) > 0 {
+			impacts = append(impacts, *s)
+		}
+	}
+
+	sort.Slice(impacts, func(i, j int) bool {
+		return impacts[i].Namespace+"/"+impacts[i].Name < impacts[j].Namespace+"/"+impacts[j].Name
+	})
+
+	return impacts, remaining, nil
+}
+
+// disruptionBudgetImpact returns the disruption budgets whose allowed disruptions are used up by the affected pods
+func (q *Quarantine) disruptionBudgetImpact(affected map[string]corev1.Pod) ([]v1alpha1.DisruptionBudgetImpact, error) {
+
+	budgets := map[string]*v1alpha1.DisruptionBudgetImpact{}
+
+	for _, pod := range affected {
+
+		pdbs, err := disruptionBudgets(q.Client, pod)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pdb := range pdbs {
+
+			key := pdb.ObjectMeta.Namespace + "/" + pdb.ObjectMeta.Name
+
+			if _, ok := budgets[key]; !ok {
+				budgets[key] = &v1alpha1.DisruptionBudgetImpact{
+					Namespace:          pdb.ObjectMeta.Namespace,
+					Name:               pdb.ObjectMeta.Name,
+					DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+				}
+			}
+
+			budgets[key].Pods++
+		}
+	}
+
+	impacts := []v1alpha1.DisruptionBudgetImpact{}
+
+	for _, b := range budgets {
+		if b.DisruptionsAllowed-b.Pods <= 0 {
+			impacts = append(impacts, *b)
+		}
+	}
+
+	sort.Slice(impacts, func(i, j int) bool {
+		return impacts[i].Namespace+"/"+impacts[i].Name < impacts[j].Namespace+"/"+impacts[j].Name
+	})
+
+	return impacts, nil
+}
+
+// ingressImpact returns the ingresses with an affected backend service whose backend services have no ready endpoints left
+func (q *Quarantine) ingressImpact(services []v1alpha1.ServiceImpact, remaining map[string]int32) ([]v1alpha1.IngressImpact, error) {
+
+	if len(services) == 0 {
+		return []v1alpha1.IngressImpact{}, nil
+	}
+
+	impacted := map[string]bool{}
+
+	for _, s := range services {
+		impacted[s.Namespace+"/"+s.Name] = true
+	}
+
+	ingresses, err := q.Client.NetworkingV1().Ingresses("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	impacts := []v1alpha1.IngressImpact{}
+
+	for _, ingress := range ingresses.Items {
+
+		backends := []string{}
+
+		if b := ingress.Spec.DefaultBackend; b != nil && b.Service != nil {
+			backends = append(backends, b.Service.Name)
+		}
+
+		for _, rule := range ingress.Spec.Rules {
+
+			if rule.HTTP == nil {
+				continue
+			}
+
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service != nil {
+					backends = append(backends, path.Backend.Service.Name)
+				}
+			}
+		}
+
+		names := []string{}
+		seen := map[string]bool{}
+		touched := false
+		served := false
+
+		for _, name := range backends {
+
+			key := ingress.ObjectMeta.Namespace + "/" + name
+
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			names = append(names, name)
+			touched = touched || impacted[key]
+			served = served || remaining[key] > 0
+		}
+
+		if touched && !served {
+			sort.Strings(names)
+			impacts = append(impacts, v1alpha1.IngressImpact{Namespace: ingress.ObjectMeta.Namespace, Name: ingress.ObjectMeta.Name, Services: names})
+		}
+	}
+
+	return impacts, nil
+}
+
+// selectedByWorkload returns if the pod belongs to one of the listed workloads
+func selectedByWorkload(pod corev1.Pod, workloads []workloadStrategy) bool {
+
+	for _, w := range workloads {
+		if w.selects(pod) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,280 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const logStreamDir = "/var/log/quarantine"
+const logStreamMaxSizeMB = 10
+const logStreamMaxFiles = 5
+
+// LogsFunc represents opening the log stream of a container of a pod
+type LogsFunc func(ctx context.Context, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+
+// LogStreamer represents following logs of isolated pods for as long as their quarantine is active
+type LogStreamer struct {
+	Dir string
+	// Logs opens the log streams, the pod logs api of the quarantine client is used if not set
+	Logs    LogsFunc
+	mu      sync.Mutex
+	streams map[string]map[string]context.CancelFunc
+}
+
+// NewLogStreamer represents an initialization of a log streamer writing to files below dir
+func NewLogStreamer(dir string) *LogStreamer {
+
+	if dir == "" {
+		dir = logStreamDir
+	}
+
+	return &LogStreamer{
+		Dir:     dir,
+		streams: map[string]map[string]context.CancelFunc{},
+	}
+}
+
+// Stop represents cancelling all log streams of a quarantine
+func (s *LogStreamer) Stop(key string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, cancel := range s.streams[key] {
+		cancel()
+	}
+
+	delete(s.streams, key)
+}
+
+func (q *Quarantine) streamLogs() error {
+
+	if !q.Logs.Enabled || q.Streamer == nil {
+		return nil
+	}
+
+	var pods *corev1.PodList
+	var err error
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: QuarantinePodLabelPrefix + QuarantinePodLabelKey + "=" + quarantinePodLabelValue,
+	}
+
+	if pods, err = q.Client.CoreV1().Pods("").List(context.TODO(), listOpts); err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+
+		if !q.isQuarantinedNode(pod.Spec.NodeName) {
+			continue
+		}
+
+		for _, container := range pod.Spec.Containers {
+			q.Streamer.follow(q.Client, q.Namespace+"/"+q.Name, pod, container.Name, q.Logs, q.Logger)
+		}
+	}
+
+	return nil
+}
+
+func (s *LogStreamer) follow(c kubernetes.Interface, key string, pod corev1.Pod, container string, opts Logs, logger logr.Logger) {
+
+	streamKey := pod.ObjectMeta.Namespace + "_" + pod.ObjectMeta.Name + "_" + container
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.streams[key][streamKey]; ok {
+		return
+	}
+
+	if _, ok := s.streams[key]; !ok {
+		s.streams[key] = map[string]context.CancelFunc{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.streams[key][streamKey] = cancel
+
+	path := filepath.Join(s.Dir, strings.Replace(key, "/", string(filepath.Separator), 1), streamKey+".log")
+	logOpts := &corev1.PodLogOptions{
+		Container: container,
+		Follow:    true,
+	}
+
+	// continue after the last written line if the stream was interrupted before
+	if info, err := os.Stat(path); err == nil {
+		logOpts.SinceTime = &metav1.Time{Time: info.ModTime()}
+	}
+
+	go func() {
+
+		defer s.release(ctx, key, streamKey)
+
+		streamLogger := logger.WithValues("pod", pod.ObjectMeta.Name, "container", container)
+
+		out, err := NewRotatingFile(path, int64(opts.MaxSizeMB)*1024*1024, opts.MaxFiles)
+
+		if err != nil {
+			streamLogger.Error(err, "open log file")
+			return
+		}
+
+		defer out.Close()
+
+		open := s.Logs
+
+		if open == nil {
+			open = func(ctx context.Context, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+				return c.CoreV1().Pods(namespace).GetLogs(name, opts).Stream(ctx)
+			}
+		}
+
+		stream, err := open(ctx, pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, logOpts)
+
+		if err != nil {
+			streamLogger.Error(err, "stream logs")
+			return
+		}
+
+		defer stream.Close()
+
+		streamLogger.Info("streaming logs...")
+
+		if _, err = io.Copy(out, stream); err != nil && ctx.Err() == nil {
+			streamLogger.Error(err, "stream logs")
+		}
+	}()
+}
+
+func (s *LogStreamer) release(ctx context.Context, key, streamKey string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// a stopped quarantine already removed its streams
+	if ctx.Err() != nil {
+		return
+	}
+
+	if cancel, ok := s.streams[key][streamKey]; ok {
+		cancel()
+		delete(s.streams[key], streamKey)
+	}
+}
+
+// RotatingFile represents a log file which is moved to $path.1 once it reaches its size and keeps at most maxFiles files
+type RotatingFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	size     int64
+	file     *os.File
+}
+
+// NewRotatingFile represents opening a rotating file and continuing at its end if it exists
+func NewRotatingFile(path string, maxSize int64, maxFiles int) (*RotatingFile, error) {
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+
+	r := &RotatingFile{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Write represents appending to the file and rotating it before if p exceeds its size
+func (r *RotatingFile) Write(p []byte) (int, error) {
+
+	if r.maxSize > 0 && r.size+int64(len(p)) > r.maxSize && r.size > 0 {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+// Close represents closing the current file
+func (r *RotatingFile) Close() error {
+	return r.file.Close()
+}
+
+func (r *RotatingFile) open() error {
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	r.file = f
+	r.size = info.Size()
+
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	for i := r.maxFiles - 1; i > 0; i-- {
+		older := fmt.Sprintf("%s.%d", r.path, i)
+
+		if _, err := os.Stat(older); err != nil {
+			continue
+		}
+
+		if i == r.maxFiles-1 {
+			if err := os.Remove(older); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.Rename(older, fmt.Sprintf("%s.%d", r.path, i+1)); err != nil {
+			return err
+		}
+	}
+
+	if r.maxFiles > 1 {
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+
+	return r.open()
+}
@@ -0,0 +1,55 @@
+package quarantine
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// modes for quarantining a node
+const modeAuto = "auto"
+const modeDrain = "drain"
+const modeUnreachable = "unreachable"
+const modeSoft = "soft"
+
+// parseMode sets the mode of the node. The node overrides the quarantine.
+func (n *Node) parseMode(baseMode, nodeMode string) {
+
+	n.Mode = modeAuto
+
+	if baseMode != "" {
+		n.Mode = baseMode
+	}
+
+	if nodeMode != "" {
+		n.Mode = nodeMode
+	}
+}
+
+// unreachable returns if the node is handled as unreachable. In auto mode this is the case if the ready condition of the node is false or unknown.
+func (n Node) unreachable() bool {
+
+	switch n.Mode {
+	case modeUnreachable:
+		return true
+	case modeDrain, modeSoft:
+		return false
+	}
+
+	nodeObj := n.getNodeAPIObject()
+
+	if nodeObj == nil {
+		return false
+	}
+
+	for _, condition := range nodeObj.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status != corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// soft returns if new pods are only kept away from the node while running pods are neither drained nor evicted
+func (n Node) soft() bool {
+	return n.Mode == modeSoft
+}
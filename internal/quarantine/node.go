@@ -2,40 +2,56 @@ package quarantine
 
 import (
 	"context"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/kubectl/pkg/drain"
 
 	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/audit"
+	"github.com/soer3n/incident-operator/internal/metrics"
 )
 
 const dsType = "daemonset"
 const deploymentType = "deployment"
+const metricsStepDrain = "drain"
+
+// drain defaults which are used if neither the quarantine nor the node overrides them
+const drainGracePeriodSeconds = -1
+const drainChunkSize = int64(500)
+const nodeWatchTimeoutSeconds = int64(20)
 
 func (n Node) manageWorkloads() error {
 
 	for _, ds := range n.Daemonsets {
 
-		if err := ds.manageWorkload(n.Flags.Client, n.Name, n.Isolate, n.Logger.WithValues("daemonset", ds.Name)); err != nil {
+		if err := ds.manageWorkload(n.Flags.Client, n.Name, n.Isolate, n.plan, n.events, n.audit, n.Logger.WithValues("daemonset", ds.Name)); err != nil {
 			return err
 		}
 	}
 
 	for _, d := range n.Deployments {
 
-		if err := d.manageWorkload(n.Flags.Client, n.Name, n.Isolate, n.Logger.WithValues("deployment", d.Name)); err != nil {
+		if err := d.manageWorkload(n.Flags.Client, n.Name, n.Isolate, n.plan, n.events, n.audit, n.Logger.WithValues("deployment", d.Name)); err != nil {
 			return err
 		}
 	}
 
-	if err := n.disableScheduling(); err != nil {
-		return err
+	// nodes in soft mode are only cordoned on request and prefer other nodes for new pods
+	if !n.soft() || n.SoftCordon {
+		if err := n.disableScheduling(); err != nil {
+			return err
+		}
+	}
+
+	if n.soft() {
+		return n.addTaint(corev1.TaintEffectPreferNoSchedule)
 	}
 
 	if n.Isolate {
-		if err := n.addTaint(); err != nil {
+		if err := n.addTaint(quarantineTaintEffect); err != nil {
 			return err
 		}
 	}
@@ -45,30 +61,111 @@ func (n Node) manageWorkloads() error {
 
 func (n *Node) evictPods() error {
 
-	var pods *corev1.PodList
-	var err error
+	pods, err := n.listPods()
 
-	listOpts := metav1.ListOptions{
-		FieldSelector: "spec.nodeName=" + n.Name,
+	if err != nil {
+		return err
 	}
 
-	if pods, err = n.Flags.Client.CoreV1().Pods("").List(context.TODO(), listOpts); err != nil {
+	workloads, err := n.workloadStrategies()
+
+	if err != nil {
 		return err
 	}
 
-	for _, pod := range pods.Items {
-		if pod.Spec.PriorityClassName != "system-node-critical" && podIsNotInQuarantine(pod) && n.Isolate {
-			if err := evictPod(pod, n.Flags.Client); err != nil {
+	candidates := []corev1.Pod{}
+	deletions := []corev1.Pod{}
+
+	for _, pod := range pods {
+		if excluded, reason := n.Filter.Excludes(pod); excluded {
+			n.Logger.Info("pod kept on node", "pod", pod.ObjectMeta.Namespace+"/"+pod.ObjectMeta.Name, "reason", reason)
+			continue
+		}
+
+		if pod.Spec.PriorityClassName == "system-node-critical" {
+			continue
+		}
+
+		// pods evicted or blocked by the drain are not evicted again
+		if pod.ObjectMeta.DeletionTimestamp != nil || n.hasBlocked(pod) {
+			continue
+		}
+
+		switch n.strategyFor(pod, workloads) {
+		case strategyIsolate:
+			if !podIsNotInQuarantine(pod) {
+				continue
+			}
+
+			if err := isolatePodObject(n.Flags.Client, pod, n.Name, podWorkload(pod), true, false, n.plan, n.events, n.audit); err != nil {
 				return err
 			}
+		case strategyDelete:
+			if n.plan != nil {
+				n.plan.add(n.Name, planActionDelete, podTarget(pod), "strategy "+strategyDelete)
+				continue
+			}
+
+			deletions = append(deletions, pod)
+		case strategyEvict:
+			if n.plan != nil {
+				n.plan.add(n.Name, planActionEvict, podTarget(pod), "strategy "+strategyEvict)
+
+				if err := n.planDisruptionBudgets(pod); err != nil {
+					return err
+				}
+				continue
+			}
+
+			candidates = append(candidates, pod)
 		}
 	}
 
-	return nil
+	if n.plan != nil {
+		return nil
+	}
+
+	for _, pod := range deletions {
+		if err := n.forceDelete(pod); err != nil {
+			return err
+		}
+	}
+
+	defer metrics.ObserveStep(planActionEvict, time.Now())
+
+	return n.evict(candidates)
+}
+
+// listPods returns all pods on the node listed in chunks of the configured size
+func (n *Node) listPods() ([]corev1.Pod, error) {
+
+	pods := []corev1.Pod{}
+	listOpts := metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + n.Name,
+		Limit:         n.ChunkSize,
+	}
+
+	for {
+		list, err := n.Flags.Client.CoreV1().Pods("").List(context.TODO(), listOpts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pods = append(pods, list.Items...)
+
+		if list.Continue == "" {
+			return pods, nil
+		}
+
+		listOpts.Continue = list.Continue
+	}
 }
 
 func (n *Node) update() error {
 
+	n.blocked = []v1alpha1.BlockedPod{}
+
 	ok, err := n.isAlreadyIsolated()
 
 	if err != nil {
@@ -80,6 +177,15 @@ func (n *Node) update() error {
 		return err
 	}
 
+	if n.soft() {
+		return nil
+	}
+
+	if n.unreachable() {
+		n.Logger.Info("remove pods from unreachable node...")
+		return n.removeUnreachable()
+	}
+
 	if !ok {
 		n.Logger.Info("node not isolated...")
 
@@ -121,12 +227,14 @@ func (n *Node) setNodeResources(rs []v1alpha1.Resource) {
 				Name:      r.Name,
 				Namespace: r.Namespace,
 				Keep:      r.Keep,
+				Strategy:  r.Strategy,
 			})
 		case deploymentType:
 			n.Deployments = append(n.Deployments, Deployment{
 				Name:      r.Name,
 				Namespace: r.Namespace,
 				Keep:      r.Keep,
+				Strategy:  r.Strategy,
 			})
 		}
 	}
@@ -134,63 +242,59 @@ func (n *Node) setNodeResources(rs []v1alpha1.Resource) {
 
 func (n *Node) mergeResources(rs []v1alpha1.Resource) {
 
+	// resources configured for the node take precedence over global resources with the same name
+	global := []v1alpha1.Resource{}
+
 	for _, r := range rs {
-		switch t := r.Type; t {
-		case dsType:
-			if len(n.Daemonsets) > 1 {
-				for _, v := range n.Daemonsets {
-					if v.Name == r.Name && v.Namespace == r.Namespace {
-						continue
-					}
-					n.Daemonsets = append(n.Daemonsets, Daemonset{
-						Name:      v.Name,
-						Namespace: v.Namespace,
-						Keep:      v.Keep,
-					})
+		if !n.hasResource(r) {
+			global = append(global, r)
+		}
+	}
 
-				}
-				return
-			}
-			n.Daemonsets = append(n.Daemonsets, Daemonset{
-				Name:      r.Name,
-				Namespace: r.Namespace,
-				Keep:      r.Keep,
-			})
-		case deploymentType:
-			if len(n.Deployments) > 1 {
-				for _, v := range n.Deployments {
-					if v.Name == r.Name && v.Namespace == r.Namespace {
-						continue
-					}
-					n.Deployments = append(n.Deployments, Deployment{
-						Name:      v.Name,
-						Namespace: v.Namespace,
-						Keep:      v.Keep,
-					})
+	n.setNodeResources(global)
+}
 
-				}
-				return
+func (n *Node) hasResource(r v1alpha1.Resource) bool {
+
+	switch r.Type {
+	case dsType:
+		for _, v := range n.Daemonsets {
+			if v.Name == r.Name && v.Namespace == r.Namespace {
+				return true
+			}
+		}
+	case deploymentType:
+		for _, v := range n.Deployments {
+			if v.Name == r.Name && v.Namespace == r.Namespace {
+				return true
 			}
-			n.Deployments = append(n.Deployments, Deployment{
-				Name:      r.Name,
-				Namespace: r.Namespace,
-				Keep:      r.Keep,
-			})
 		}
 	}
+
+	return false
 }
 
 func (n *Node) parseFlags(baseFlags, nodeFlags v1alpha1.Flags) {
 
 	falseFlag := false
 	trueFlag := true
+	noTimeout := int64(0)
+	gracePeriod := drainGracePeriodSeconds
+	skipWait := 0
+	chunkSize := drainChunkSize
+	watchTimeout := nodeWatchTimeoutSeconds
 
 	flags := v1alpha1.Flags{
-		DeleteEmptyDirData:  &trueFlag,
-		DisableEviction:     &falseFlag,
-		Force:               &falseFlag,
-		IgnoreAllDaemonSets: &falseFlag,
-		IgnoreErrors:        &falseFlag,
+		DeleteEmptyDirData:              &trueFlag,
+		DisableEviction:                 &falseFlag,
+		Force:                           &falseFlag,
+		IgnoreAllDaemonSets:             &falseFlag,
+		IgnoreErrors:                    &falseFlag,
+		TimeoutSeconds:                  &noTimeout,
+		GracePeriodSeconds:              &gracePeriod,
+		SkipWaitForDeleteTimeoutSeconds: &skipWait,
+		ChunkSize:                       &chunkSize,
+		WatchTimeoutSeconds:             &watchTimeout,
 	}
 
 	flags = n.mergeFlags(flags, baseFlags)
@@ -201,6 +305,11 @@ func (n *Node) parseFlags(baseFlags, nodeFlags v1alpha1.Flags) {
 	n.Flags.Force = *flags.Force
 	n.Flags.IgnoreAllDaemonSets = *flags.IgnoreAllDaemonSets
 	n.Flags.IgnoreErrors = *flags.IgnoreErrors
+	n.Flags.Timeout = time.Duration(*flags.TimeoutSeconds) * time.Second
+	n.Flags.GracePeriodSeconds = *flags.GracePeriodSeconds
+	n.Flags.SkipWaitForDeleteTimeoutSeconds = *flags.SkipWaitForDeleteTimeoutSeconds
+	n.ChunkSize = *flags.ChunkSize
+	n.WatchTimeoutSeconds = *flags.WatchTimeoutSeconds
 }
 
 func (n *Node) mergeFlags(baseFlags, mergeFlags v1alpha1.Flags) v1alpha1.Flags {
@@ -225,12 +334,40 @@ func (n *Node) mergeFlags(baseFlags, mergeFlags v1alpha1.Flags) v1alpha1.Flags {
 		baseFlags.IgnoreErrors = mergeFlags.IgnoreErrors
 	}
 
+	if mergeFlags.TimeoutSeconds != nil {
+		baseFlags.TimeoutSeconds = mergeFlags.TimeoutSeconds
+	}
+
+	if mergeFlags.GracePeriodSeconds != nil {
+		baseFlags.GracePeriodSeconds = mergeFlags.GracePeriodSeconds
+	}
+
+	if mergeFlags.SkipWaitForDeleteTimeoutSeconds != nil {
+		baseFlags.SkipWaitForDeleteTimeoutSeconds = mergeFlags.SkipWaitForDeleteTimeoutSeconds
+	}
+
+	if mergeFlags.ChunkSize != nil {
+		baseFlags.ChunkSize = mergeFlags.ChunkSize
+	}
+
+	if mergeFlags.WatchTimeoutSeconds != nil {
+		baseFlags.WatchTimeoutSeconds = mergeFlags.WatchTimeoutSeconds
+	}
+
 	return baseFlags
 }
 
 func (n Node) disableScheduling() error {
 
+	if n.plan != nil {
+		n.plan.add(n.Name, planActionCordon, "node/"+n.Name, "spec.unschedulable=true")
+		return nil
+	}
+
+	defer metrics.ObserveStep(planActionCordon, time.Now())
+
 	nodeObj := n.getNodeAPIObject()
+	before := nodeObj.DeepCopy()
 
 	n.Logger.Info("cordon...")
 
@@ -238,7 +375,13 @@ func (n Node) disableScheduling() error {
 		return err
 	}
 
-	timeout := int64(20)
+	if !before.Spec.Unschedulable {
+		n.audit.Add(audit.VerbPatch, "Node", "", n.Name, before, nodeObj)
+	}
+
+	n.events.emit(nodeObj, eventReasonCordoned, "node %s cordoned", n.Name)
+
+	timeout := n.WatchTimeoutSeconds
 	listOpts := metav1.ListOptions{
 		Watch:          true,
 		LabelSelector:  "kubernetes.io/hostname=" + n.Name,
@@ -258,6 +401,7 @@ func (n Node) disableScheduling() error {
 func (n Node) enableScheduling() error {
 
 	nodeObj := n.getNodeAPIObject()
+	before := nodeObj.DeepCopy()
 
 	n.Logger.Info("uncordon...")
 
@@ -266,6 +410,10 @@ func (n Node) enableScheduling() error {
 		return err
 	}
 
+	if before.Spec.Unschedulable {
+		n.audit.Add(audit.VerbPatch, "Node", "", n.Name, before, nodeObj)
+	}
+
 	if err := n.waitForUpdate(); err != nil {
 		return err
 	}
@@ -273,26 +421,43 @@ func (n Node) enableScheduling() error {
 	return nil
 }
 
-func (n Node) addTaint() error {
+func (n Node) addTaint(effect corev1.TaintEffect) error {
 
 	nodeObj := n.getNodeAPIObject()
+	taints := []corev1.Taint{}
 
+	// a quarantine taint with another effect is replaced when the mode of the node changed
 	for _, taint := range nodeObj.Spec.Taints {
 		if taint.Key == quarantineTaintKey && taint.Value == quarantineTaintValue {
-			return nil
+			if taint.Effect == effect {
+				return nil
+			}
+			continue
 		}
+
+		taints = append(taints, taint)
+	}
+
+	if n.plan != nil {
+		n.plan.add(n.Name, planActionTaint, "node/"+n.Name, quarantineTaintKey+"="+quarantineTaintValue+":"+string(effect))
+		return nil
 	}
 
-	nodeObj.Spec.Taints = append(nodeObj.Spec.Taints, corev1.Taint{
+	defer metrics.ObserveStep(planActionTaint, time.Now())
+
+	before := nodeObj.DeepCopy()
+	nodeObj.Spec.Taints = append(taints, corev1.Taint{
 		Key:    quarantineTaintKey,
 		Value:  quarantineTaintValue,
-		Effect: quarantineTaintEffect,
+		Effect: effect,
 	})
 
-	if err := n.updateNodeAPIObject(nodeObj); err != nil {
+	if err := n.updateNodeAPIObject(before, nodeObj); err != nil {
 		return err
 	}
 
+	n.events.emit(nodeObj, eventReasonTainted, "node %s tainted with %s=%s:%s", n.Name, quarantineTaintKey, quarantineTaintValue, effect)
+
 	if err := n.waitForUpdate(); err != nil {
 		return err
 	}
@@ -303,6 +468,7 @@ func (n Node) addTaint() error {
 func (n Node) removeTaint() error {
 
 	nodeObj := n.getNodeAPIObject()
+	before := nodeObj.DeepCopy()
 	taints := []corev1.Taint{}
 
 	for _, taint := range nodeObj.Spec.Taints {
@@ -313,7 +479,10 @@ func (n Node) removeTaint() error {
 
 	nodeObj.Spec.Taints = taints
 
-	if err := n.updateNodeAPIObject(nodeObj); err != nil {
+	// the out-of-service taint of an unreachable node is removed together with the quarantine taint if the quarantine added it
+	withoutOutOfServiceTaint(nodeObj)
+
+	if err := n.updateNodeAPIObject(before, nodeObj); err != nil {
 		return err
 	}
 
@@ -324,12 +493,34 @@ func (n Node) removeTaint() error {
 	return nil
 }
 
-func (n Node) deschedulePods() error {
-	if err := drain.RunNodeDrain(n.Flags, n.Name); err != nil {
+func (n *Node) deschedulePods() error {
+
+	if err := n.setDrainFilters(); err != nil {
+		return err
+	}
+
+	if n.plan != nil {
+		return n.planDrain()
+	}
+
+	defer metrics.ObserveStep(metricsStepDrain, time.Now())
+
+	var err error
+
+	if len(n.Waves) > 0 {
+		err = n.drainWaves()
+	} else {
+		_, err = n.drainPods()
+	}
+
+	if err != nil {
 		n.Logger.Error(err, "deschedule workloads")
+		metrics.EvictionFailed(err)
 		return err
 	}
 
+	n.events.emit(n.getNodeAPIObject(), eventReasonDrained, "node %s drained", n.Name)
+
 	if err := n.waitForUpdate(); err != nil {
 		return err
 	}
@@ -337,6 +528,27 @@ func (n Node) deschedulePods() error {
 	return nil
 }
 
+// drainPods represents evicting the pods selected by the drain filters. The pods are evicted like pods with the evict strategy
+// so that a pod blocked by a disruption budget is recorded after the drain timeout instead of blocking the drain.
+func (n *Node) drainPods() ([]corev1.Pod, error) {
+
+	list, errs := n.Flags.GetPodsForDeletion(n.Name)
+
+	if errs != nil {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	pods := list.Pods()
+
+	if err := n.evict(pods); err != nil {
+		return pods, err
+	}
+
+	n.waitForDeletion(pods)
+
+	return pods, nil
+}
+
 func (n Node) getNodeAPIObject() *corev1.Node {
 
 	var err error
@@ -351,22 +563,25 @@ func (n Node) getNodeAPIObject() *corev1.Node {
 	return nodeObj
 }
 
-func (n Node) updateNodeAPIObject(nodeObj *corev1.Node) error {
+func (n Node) updateNodeAPIObject(before, nodeObj *corev1.Node) error {
 
+	var updated *corev1.Node
 	var err error
 
 	opts := metav1.UpdateOptions{}
 
-	if _, err = n.Flags.Client.CoreV1().Nodes().Update(context.TODO(), nodeObj, opts); err != nil {
+	if updated, err = n.Flags.Client.CoreV1().Nodes().Update(context.TODO(), nodeObj, opts); err != nil {
 		n.Logger.Error(err, "update node")
 		return err
 	}
 
+	n.audit.Add(audit.VerbUpdate, "Node", "", n.Name, before, updated)
+
 	return nil
 }
 
 func (n Node) waitForUpdate() error {
-	timeout := int64(20)
+	timeout := n.WatchTimeoutSeconds
 	listOpts := metav1.ListOptions{
 		Watch:          true,
 		LabelSelector:  "kubernetes.io/hostname=" + n.Name,
@@ -0,0 +1,114 @@
+package quarantine
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+)
+
+const planActionSnapshot = "snapshot"
+const planActionDebug = "debug"
+const planActionCompare = "compare"
+const planActionRelabel = "relabel"
+const planActionToleration = "toleration"
+const planActionCordon = "cordon"
+const planActionTaint = "taint"
+const planActionEvict = "evict"
+const planActionDelete = "delete"
+const planActionBlocker = "blocker"
+const planActionUnschedulable = "unschedulable"
+const planActionImpact = "impact"
+
+// Plan represents the actions a quarantine would apply to the cluster in dry run mode
+type Plan struct {
+	Actions []v1alpha1.PlannedAction
+}
+
+func (p *Plan) add(node, kind, target, detail string) {
+
+	if p.has(node, kind, target) {
+		return
+	}
+
+	p.Actions = append(p.Actions, v1alpha1.PlannedAction{
+		Node:   node,
+		Kind:   kind,
+		Target: target,
+		Detail: detail,
+	})
+}
+
+func (p *Plan) has(node, kind, target string) bool {
+
+	for _, a := range p.Actions {
+		if a.Node == node && a.Kind == kind && a.Target == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+func podTarget(pod corev1.Pod) string {
+	return "pod/" + pod.ObjectMeta.Namespace + "/" + pod.ObjectMeta.Name
+}
+
+// planDrain records the pods a drain of the node would remove and the disruption budgets blocking them
+func (n Node) planDrain() error {
+
+	list, errs := n.Flags.GetPodsForDeletion(n.Name)
+
+	for _, err := range errs {
+		n.plan.add(n.Name, planActionBlocker, "node/"+n.Name, err.Error())
+	}
+
+	if list == nil {
+		return nil
+	}
+
+	kind := planActionEvict
+
+	if n.Flags.DisableEviction {
+		kind = planActionDelete
+	}
+
+	for _, p := range list.Pods() {
+
+		// relabeled pods are not matched by the pod selector of the drain anymore
+		if n.plan.has(n.Name, planActionRelabel, podTarget(p)) {
+			continue
+		}
+
+		detail := "drain"
+
+		if i := n.waveIndex(p); i >= 0 {
+			detail = "drain wave " + n.Waves[i].Name
+		}
+
+		n.plan.add(n.Name, kind, podTarget(p), detail)
+
+		if err := n.planDisruptionBudgets(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n Node) planDisruptionBudgets(pod corev1.Pod) error {
+
+	pdbs, err := disruptionBudgets(n.Flags.Client, pod)
+
+	if err != nil {
+		return err
+	}
+
+	for _, pdb := range pdbs {
+
+		if pdb.Status.DisruptionsAllowed < 1 {
+			n.plan.add(n.Name, planActionBlocker, podTarget(pod), "poddisruptionbudget "+pdb.ObjectMeta.Namespace+"/"+pdb.ObjectMeta.Name+" allows no disruptions")
+		}
+	}
+
+	return nil
+}
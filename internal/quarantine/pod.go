@@ -2,14 +2,14 @@ package quarantine
 
 import (
 	"context"
-	"errors"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/descheduler/pkg/descheduler/evictions"
 
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/soer3n/incident-operator/internal/audit"
 )
 
 const QuarantinePodLabelPrefix = "ops.soer3n.info/"
@@ -17,7 +17,13 @@ const QuarantinePodLabelKey = "quarantine"
 const QuarantineNodeRemoveLabel = "revert"
 const quarantinePodLabelValue = "true"
 
-func updatePod(c kubernetes.Interface, matchedLabels map[string]string, nodeName, namespace string, updateLabels, addToleration bool) error {
+// QuarantinePodWorkloadAnnotation is set on isolated pods since their owner is released together with the labels
+const QuarantinePodWorkloadAnnotation = "workload"
+
+// QuarantineAdoptedAnnotation is set on quarantines created for orphaned nodes which are taken over as they are
+const QuarantineAdoptedAnnotation = "adopted"
+
+func updatePod(c kubernetes.Interface, matchedLabels map[string]string, nodeName, namespace, workload string, updateLabels, addToleration bool, plan *Plan, events *Events, trail *audit.Trail) error {
 
 	var pods *corev1.PodList
 	var err error
@@ -39,32 +45,65 @@ func updatePod(c kubernetes.Interface, matchedLabels map[string]string, nodeName
 
 	for _, pod := range pods.Items {
 		if pod.Spec.NodeName == nodeName {
+			if err := isolatePodObject(c, pod, nodeName, workload, updateLabels, addToleration, plan, events, trail); err != nil {
+				return err
+			}
+		}
+	}
 
-			currentPod := &corev1.Pod{}
-			pod.DeepCopyInto(currentPod)
+	return nil
+}
 
-			updateOpts := metav1.UpdateOptions{}
+// isolatePodObject represents relabeling a single pod so that it is released by its workload and adding the quarantine toleration to it
+func isolatePodObject(c kubernetes.Interface, pod corev1.Pod, nodeName, workload string, updateLabels, addToleration bool, plan *Plan, events *Events, trail *audit.Trail) error {
 
-			if updateLabels {
-				labels := map[string]string{}
-				labels[QuarantinePodLabelPrefix+QuarantinePodLabelKey] = quarantinePodLabelValue
-				currentPod.ObjectMeta.Labels = labels
-			}
+	var err error
 
-			if addToleration {
-				currentPod.Spec.Tolerations = append(pod.Spec.Tolerations, corev1.Toleration{
-					Key:      quarantineTaintKey,
-					Operator: quarantineTaintOperator,
-					Effect:   quarantineTaintEffect,
-				})
-			}
+	if plan != nil {
+		if updateLabels {
+			plan.add(nodeName, planActionRelabel, podTarget(pod), QuarantinePodLabelPrefix+QuarantinePodLabelKey+"="+quarantinePodLabelValue)
+		}
 
-			if _, err = c.CoreV1().Pods(namespace).Update(context.TODO(), currentPod, updateOpts); err != nil {
-				return err
-			}
+		if addToleration {
+			plan.add(nodeName, planActionToleration, podTarget(pod), quarantineTaintKey+":"+quarantineTaintEffect)
+		}
+		return nil
+	}
+
+	namespace := pod.ObjectMeta.Namespace
+	currentPod := &corev1.Pod{}
+	pod.DeepCopyInto(currentPod)
+
+	updateOpts := metav1.UpdateOptions{}
+
+	if updateLabels {
+		labels := map[string]string{}
+		labels[QuarantinePodLabelPrefix+QuarantinePodLabelKey] = quarantinePodLabelValue
+		currentPod.ObjectMeta.Labels = labels
+
+		if currentPod.ObjectMeta.Annotations == nil {
+			currentPod.ObjectMeta.Annotations = map[string]string{}
 		}
+
+		currentPod.ObjectMeta.Annotations[QuarantinePodLabelPrefix+QuarantinePodWorkloadAnnotation] = workload
+	}
+
+	if addToleration {
+		currentPod.Spec.Tolerations = append(pod.Spec.Tolerations, corev1.Toleration{
+			Key:      quarantineTaintKey,
+			Operator: quarantineTaintOperator,
+			Effect:   quarantineTaintEffect,
+		})
+	}
+
+	if currentPod, err = c.CoreV1().Pods(namespace).Update(context.TODO(), currentPod, updateOpts); err != nil {
+		return err
 	}
 
+	trail.Add(audit.VerbUpdate, "Pod", namespace, currentPod.ObjectMeta.Name, &pod, currentPod)
+
+	events.emit(currentPod, eventReasonPodIsolated, "pod %s/%s on node %s isolated from %s", namespace, currentPod.ObjectMeta.Name, nodeName, workload)
+
 	return nil
 }
 
@@ -76,7 +115,7 @@ func podIsNotInQuarantine(pod corev1.Pod) bool {
 	return false
 }
 
-func cleanupIsolatedPods(c kubernetes.Interface) error {
+func cleanupIsolatedPods(c kubernetes.Interface, trail *audit.Trail) error {
 
 	var pods *corev1.PodList
 	var err error
@@ -96,26 +135,8 @@ func cleanupIsolatedPods(c kubernetes.Interface) error {
 		if err = c.CoreV1().Pods(pod.ObjectMeta.Namespace).Delete(context.TODO(), pod.ObjectMeta.Name, deleteOpts); err != nil {
 			return err
 		}
-	}
-
-	return nil
-}
-
-func evictPod(pod corev1.Pod, c kubernetes.Interface) error {
-
-	var err error
-	var success bool
-	var excludedNodesObj []*corev1.Node
-	var node *corev1.Node
-
-	ev := evictions.NewPodEvictor(c, "", false, 1, excludedNodesObj, false, false, true)
-
-	if success, err = ev.EvictPod(context.TODO(), &pod, node, rescheduleStrategy); err != nil {
-		return err
-	}
 
-	if !success {
-		return errors.New("no success on pod eviction")
+		trail.Add(audit.VerbDelete, "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, &pod, nil)
 	}
 
 	return nil
@@ -5,15 +5,18 @@ import (
 	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/drain"
 
 	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/audit"
 
 	meta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,12 +29,16 @@ const quarantineTaintOperator = "Exists"
 const quarantineTaintEffect = "NoSchedule"
 const quarantineStatusActiveKey = "active"
 const quarantineStatusActiveMessage = "success"
+const quarantineNameLabelKey = "name"
 
 // New represents an initialization of a quarantine struct
 func New(s *v1alpha1.Quarantine, c kubernetes.Interface, f util.Factory, reqLogger logr.Logger) (*Quarantine, error) {
 
 	debugImage := debugPodImage
 	debugNamespace := debugPodNamespace
+	poolLabel := comparePoolLabel
+	logMaxSize := logStreamMaxSizeMB
+	logMaxFiles := logStreamMaxFiles
 
 	if s.Spec.Debug.Image != "" {
 		debugImage = s.Spec.Debug.Image
@@ -41,17 +48,120 @@ func New(s *v1alpha1.Quarantine, c kubernetes.Interface, f util.Factory, reqLogg
 		debugNamespace = s.Spec.Debug.Namespace
 	}
 
+	if s.Spec.Compare.PoolLabel != "" {
+		poolLabel = s.Spec.Compare.PoolLabel
+	}
+
+	if s.Spec.Logs.MaxSizeMB > 0 {
+		logMaxSize = s.Spec.Logs.MaxSizeMB
+	}
+
+	if s.Spec.Logs.MaxFiles > 0 {
+		logMaxFiles = s.Spec.Logs.MaxFiles
+	}
+
 	q := &Quarantine{
+		Name:      s.ObjectMeta.Name,
+		Namespace: s.ObjectMeta.Namespace,
+		DryRun:    s.Spec.DryRun,
 		Debug: Debug{
 			Enabled:   s.Spec.Debug.Enabled,
 			Image:     debugImage,
 			Namespace: debugNamespace,
 		},
-		Client:     c,
-		isActive:   false,
-		Conditions: s.Status.Conditions,
-		Logger:     reqLogger,
+		Compare: Compare{
+			Enabled:       s.Spec.Compare.Enabled,
+			ReferenceNode: s.Spec.Compare.ReferenceNode,
+			PoolLabel:     poolLabel,
+			Timeout:       compareTimeout,
+		},
+		Logs: Logs{
+			Enabled:   s.Spec.Logs.Enabled,
+			MaxSizeMB: logMaxSize,
+			MaxFiles:  logMaxFiles,
+		},
+		Client:          c,
+		isActive:        false,
+		adopted:         s.ObjectMeta.Annotations[QuarantinePodLabelPrefix+QuarantineAdoptedAnnotation] != "",
+		spec:            s.Spec,
+		started:         s.Status.Nodes,
+		created:         s.ObjectMeta.CreationTimestamp.Time,
+		Conditions:      s.Status.Conditions,
+		Comparisons:     s.Status.Comparisons,
+		Artifacts:       s.Status.Artifacts,
+		VolumeSnapshots: s.Status.VolumeSnapshots,
+		Degraded:        s.Status.DegradedWorkloads,
+		Unschedulable:   s.Status.UnschedulablePods,
+		Impact:          s.Status.Impact,
+		removed:         &removedWorkloads{},
+		factory:         f,
+		Logger:          reqLogger,
 	}
+
+	q.Snapshots = Snapshots{
+		Enabled:                 s.Spec.Snapshots.Enabled,
+		VolumeSnapshotClassName: s.Spec.Snapshots.VolumeSnapshotClassName,
+		Timeout:                 snapshotTimeout,
+	}
+
+	if s.Spec.Snapshots.Retention != nil {
+		q.Snapshots.Retention = s.Spec.Snapshots.Retention.Duration
+	}
+
+	if s.Spec.Snapshots.TimeoutSeconds > 0 {
+		q.Snapshots.Timeout = time.Duration(s.Spec.Snapshots.TimeoutSeconds) * time.Second
+	}
+
+	q.Verification = Verification{
+		Enabled: !s.Spec.Verification.Disabled,
+		Timeout: verificationTimeout,
+	}
+
+	if s.Spec.Verification.TimeoutSeconds > 0 {
+		q.Verification.Timeout = time.Duration(s.Spec.Verification.TimeoutSeconds) * time.Second
+	}
+
+	if s.Spec.Compare.TimeoutSeconds > 0 {
+		q.Compare.Timeout = time.Duration(s.Spec.Compare.TimeoutSeconds) * time.Second
+	}
+
+	q.Capacity = Capacity{
+		Enabled: s.Spec.Capacity.Enabled,
+		Block:   s.Spec.Capacity.Block,
+	}
+
+	q.ImpactAnalysis = ImpactAnalysis{
+		Enabled: s.Spec.Impact.Enabled,
+	}
+
+	q.Evidence = Evidence{
+		SigningKeySecret: s.Spec.Evidence.SigningKeySecret,
+	}
+
+	if s.Spec.Artifacts != nil {
+		q.ArtifactSink = &ArtifactSink{
+			Endpoint:          s.Spec.Artifacts.Endpoint,
+			Bucket:            s.Spec.Artifacts.Bucket,
+			Region:            s.Spec.Artifacts.Region,
+			Prefix:            s.Spec.Artifacts.Prefix,
+			CredentialsSecret: s.Spec.Artifacts.CredentialsSecret,
+			Insecure:          s.Spec.Artifacts.Insecure,
+		}
+	}
+	q.Events = &Events{
+		object: s,
+	}
+
+	q.Progress = &Progress{}
+
+	q.Audit = audit.NewTrail(s.ObjectMeta.Namespace + "/" + s.ObjectMeta.Name)
+
+	if q.DryRun {
+		q.Plan = &Plan{
+			Actions: []v1alpha1.PlannedAction{},
+		}
+	}
+
 	nodes := []*Node{}
 
 	for _, n := range s.Spec.Nodes {
@@ -59,11 +169,24 @@ func New(s *v1alpha1.Quarantine, c kubernetes.Interface, f util.Factory, reqLogg
 		temp.setNodeResources(n.Resources)
 		temp.mergeResources(s.Spec.Resources)
 		temp.parseFlags(s.Spec.Flags, n.Flags)
+		temp.parseStrategy(s.Spec.DefaultStrategy, n.DefaultStrategy)
+		temp.parseMode(s.Spec.Mode, n.Mode)
+		temp.SoftCordon = s.Spec.Soft.Cordon
+
+		if err := temp.parseFilters(s.Spec.Filters, n.Filters); err != nil {
+			return nil, err
+		}
+
+		if err := temp.parseWaves(s.Spec.Drain.Waves); err != nil {
+			return nil, err
+		}
+
 		nodes = append(nodes, temp)
 		q.Logger.Info("node added to cr", "node", n.Name)
 	}
 
 	q.Nodes = nodes
+	q.Progress.nodes = nodes
 
 	nodesToRemove := []string{}
 	nodesToRemoveObj := []*Node{}
@@ -80,9 +203,8 @@ func New(s *v1alpha1.Quarantine, c kubernetes.Interface, f util.Factory, reqLogg
 
 	q.MarkedNodes = nodesToRemoveObj
 
-	if len(s.Status.Conditions) > 0 {
-		q.isActive = true
-	}
+	// a quarantine paused by a guard or only planned has not touched its nodes yet
+	q.isActive = IsStarted(s)
 
 	return q, nil
 }
@@ -97,14 +219,21 @@ func (q Quarantine) getNodeStruct(name, debugImage, debugNamespace string, isola
 			Image:     debugImage,
 			Namespace: debugNamespace,
 		},
-		Isolate: isolate,
+		Isolate:             isolate,
+		ChunkSize:           drainChunkSize,
+		WatchTimeoutSeconds: nodeWatchTimeoutSeconds,
 		IOStreams: genericclioptions.IOStreams{
 			In:     os.Stdin,
 			Out:    os.Stdout,
 			ErrOut: os.Stdout,
 		},
-		factory: f,
-		Logger:  q.Logger.WithValues("node", name),
+		factory:  f,
+		plan:     q.Plan,
+		events:   q.Events,
+		progress: q.Progress,
+		audit:    q.Audit,
+		removed:  q.removed,
+		Logger:   q.Logger.WithValues("node", name),
 		Flags: &drain.Helper{
 			IgnoreAllDaemonSets: true,
 			DisableEviction:     false,
@@ -112,26 +241,88 @@ func (q Quarantine) getNodeStruct(name, debugImage, debugNamespace string, isola
 			PodSelector:         "!" + QuarantinePodLabelPrefix + quarantinePodSelector,
 			Force:               false,
 			IgnoreErrors:        false,
+			GracePeriodSeconds:  drainGracePeriodSeconds,
 			Ctx:                 context.TODO(),
 			Client:              q.Client,
 			ErrOut:              os.Stdout,
 			Out:                 os.Stdout,
+			OnPodDeletedOrEvicted: func(pod *corev1.Pod, usingEviction bool) {
+				verb := audit.VerbDelete
+
+				if usingEviction {
+					verb = audit.VerbEvict
+				}
+
+				q.Audit.Add(verb, "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, pod, nil)
+				q.removed.add(pod)
+			},
 		},
 	}
 }
 
 // Prepare represents the tasks before a quarantine can be started
-func (q *Quarantine) Prepare() error {
+func (q *Quarantine) Prepare() (err error) {
+
+	defer q.flushAudit()
+
+	// artifacts of a successful preparation are exported after the start
+	defer func() {
+		if err != nil {
+			q.exportPendingArtifacts()
+		}
+	}()
+
+	// nodes of an adopted quarantine were already quarantined by the deleted quarantine
+	if q.adopted {
+		q.Logger.Info("adopted quarantine, nodes are taken over as they are...")
+		return nil
+	}
+
+	// the impact on services and the capacity of the remaining nodes are analysed before any node is cordoned
+	if q.ImpactAnalysis.Enabled {
+		q.Logger.Info("analyse impact on services...")
+		if err := q.analyseImpact(); err != nil {
+			return err
+		}
+	}
+
+	if q.Capacity.Enabled {
+		q.Logger.Info("simulate rescheduling of pods...")
+		if err := q.checkCapacity(); err != nil {
+			return err
+		}
+	}
 
 	for _, n := range q.Nodes {
 
 		q.Logger.Info("preparing node...", "node", n.Name)
 
-		if q.Debug.Enabled || n.Debug.Enabled {
+		// volumes are snapshotted before anything else on the node is touched
+		if q.Snapshots.Enabled {
+			q.Logger.Info("snapshot volumes of isolated pods...", "node", n.Name)
+			if err := q.snapshotVolumes(n); err != nil {
+				return err
+			}
+		}
+
+		if (q.Debug.Enabled || n.Debug.Enabled) && q.DryRun {
+			q.Plan.add(n.Name, planActionDebug, "pod/"+q.Debug.Namespace+"/"+debugPodName+"-"+n.Name, q.Debug.Image)
+		} else if q.Debug.Enabled || n.Debug.Enabled {
 			q.Logger.Info("deploying debug pod...", "node", n.Name)
-			if err := q.Debug.deploy(n.Flags.Client, n.Name); err != nil {
+			if err := q.Debug.deploy(n.Flags.Client, n.Name, q.Audit); err != nil {
 				return err
 			}
+
+			q.Events.emit(nil, eventReasonDebugPodCreated, "debug pod %s/%s-%s deployed on node %s", q.Debug.Namespace, debugPodName, n.Name, n.Name)
+		}
+
+		if q.Compare.Enabled && q.DryRun {
+			q.Plan.add(n.Name, planActionCompare, "node/"+n.Name, "collect node state on debug pods")
+		} else if q.Compare.Enabled {
+			q.Logger.Info("comparing with reference node...", "node", n.Name)
+			comparison := q.compareNode(n)
+			q.setComparison(comparison)
+			q.addComparisonArtifact(comparison)
 		}
 
 		if ok, err := n.isAlreadyIsolated(); !ok {
@@ -155,8 +346,29 @@ func (q *Quarantine) Prepare() error {
 // Start represents the tasks to start isolating resources on nodes
 func (q *Quarantine) Start() error {
 
+	defer q.flushAudit()
+	defer q.exportPendingArtifacts()
+
+	// pods kept or isolated on the nodes of an adopted quarantine are neither drained nor evicted again
+	if q.adopted {
+		return nil
+	}
+
 	for _, n := range q.Nodes {
 
+		if n.soft() {
+			q.Logger.Info("soft quarantine, pods are kept on node...", "node", n.Name)
+			continue
+		}
+
+		if n.unreachable() {
+			q.Logger.Info("remove pods from unreachable node...", "node", n.Name)
+			if err := n.removeUnreachable(); err != nil {
+				return err
+			}
+			continue
+		}
+
 		q.Logger.Info("deschedule pods...", "node", n.Name)
 		if err := n.deschedulePods(); err != nil {
 			return err
@@ -168,38 +380,109 @@ func (q *Quarantine) Start() error {
 		}
 	}
 
-	return nil
+	if q.DryRun {
+		return nil
+	}
+
+	if err := q.blockedPodsError(); err != nil {
+		return err
+	}
+
+	if q.Verification.Enabled {
+		if err := q.verify(); err != nil {
+			return err
+		}
+	}
+
+	q.Logger.Info("stream logs of isolated pods...")
+	return q.streamLogs()
 }
 
 // Update represents the tasks which are not yet executed
 func (q *Quarantine) Update() error {
 
+	defer q.flushAudit()
+
 	for _, n := range q.MarkedNodes {
 		if q.Debug.Enabled || n.Debug.Enabled {
 			q.Logger.Info("remove debug pods...")
-			q.Debug.remove(q.Client, n.Name, q.Logger)
+			q.Debug.remove(q.Client, n.Name, q.Audit, q.Logger)
 		}
 
 		if err := n.remove(); err != nil {
 			q.Logger.Info("remove marked node", "node", n.Name)
 			return err
 		}
+
+		q.Events.emit(n.getNodeAPIObject(), eventReasonReleased, "node %s released from quarantine", n.Name)
+	}
+
+	if q.IsDegraded() {
+		q.Logger.Info("recheck degraded workloads...")
+		if err := q.recheck(); err != nil {
+			return err
+		}
+	}
+
+	nodes := q.Nodes
+	added := q.addedNodes()
+
+	// nodes added to an active quarantine are checked against the current cluster before they are touched
+	if len(added) > 0 {
+		if err := q.CheckSafety(); err != nil {
+			return err
+		}
+
+		if q.Capacity.Enabled {
+			q.Logger.Info("simulate rescheduling of pods...")
+			if err := q.checkCapacity(); err != nil {
+				return err
+			}
+		}
 	}
 
-	// limit update to fix failed reconciles
+	// limit update to added nodes and failed reconciles
 	if meta.IsStatusConditionPresentAndEqual(q.Conditions, quarantineStatusActiveKey, metav1.ConditionTrue) &&
 		q.Conditions[0].Message == quarantineStatusActiveMessage {
-		return nil
+
+		if len(added) == 0 {
+			return q.streamLogs()
+		}
+
+		nodes = added
 	}
 
-	for _, n := range q.Nodes {
+	for _, n := range nodes {
 		q.Logger.Info("update node", "node", n.Name)
 		if err := n.update(); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return q.blockedPodsError()
+}
+
+// addedNodes returns the nodes which were added to the quarantine after it was started
+func (q Quarantine) addedNodes() []*Node {
+
+	nodes := []*Node{}
+
+	for _, n := range q.Nodes {
+
+		started := false
+
+		for _, name := range q.started {
+			if name == n.Name {
+				started = true
+			}
+		}
+
+		if !started {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return nodes
 }
 
 // Stop represents the tasks for uncordon nodes, rescheduling resources and deleting debug resources
@@ -209,34 +492,51 @@ func (q *Quarantine) Stop() error {
 		return errors.New("no nodes detected")
 	}
 
+	// nothing was changed by a dry run
+	if q.DryRun {
+		return nil
+	}
+
+	defer q.flushAudit()
+
+	if q.Streamer != nil {
+		q.Logger.Info("stop streaming logs...")
+		q.Streamer.Stop(q.Namespace + "/" + q.Name)
+	}
+
+	q.addLogArtifacts()
+	q.exportPendingArtifacts()
+
 	for _, n := range q.Nodes {
 
 		if q.Debug.Enabled || n.Debug.Enabled {
 			q.Logger.Info("remove debug pods...")
-			q.Debug.remove(q.Client, n.Name, q.Logger)
+			q.Debug.remove(q.Client, n.Name, q.Audit, q.Logger)
 		}
 
 		if err := n.remove(); err != nil {
 			return err
 		}
 
+		q.Events.emit(n.getNodeAPIObject(), eventReasonReleased, "node %s released from quarantine", n.Name)
+
 		for _, ds := range n.Daemonsets {
 			q.Logger.Info("remove toleration for daemonset...", "dameonset", ds.Name)
-			if err := ds.removeToleration(n.Flags.Client); err != nil {
+			if err := ds.removeToleration(n.Flags.Client, q.Audit); err != nil {
 				return err
 			}
 		}
 
 		for _, d := range n.Deployments {
 			q.Logger.Info("remove toleration for deployment...", "deployment", d.Name)
-			if err := d.removeToleration(n.Flags.Client); err != nil {
+			if err := d.removeToleration(n.Flags.Client, q.Audit); err != nil {
 				return err
 			}
 		}
 	}
 
 	q.Logger.Info("clean up isolated pods...")
-	if err := cleanupIsolatedPods(q.Client); err != nil {
+	if err := cleanupIsolatedPods(q.Client, q.Audit); err != nil {
 		return err
 	}
 
@@ -247,3 +547,66 @@ func (q *Quarantine) Stop() error {
 func (q Quarantine) IsActive() bool {
 	return q.isActive
 }
+
+// NodeNames represents the names of all nodes which are quarantined and not marked to be removed
+func (q Quarantine) NodeNames() []string {
+
+	names := []string{}
+
+	for _, n := range q.Nodes {
+
+		marked := false
+
+		for _, m := range q.MarkedNodes {
+			if m.Name == n.Name {
+				marked = true
+			}
+		}
+
+		if !marked {
+			names = append(names, n.Name)
+		}
+	}
+
+	return names
+}
+
+// UpdateStatus represents writing results collected by the quarantine into the resource status
+func (q Quarantine) UpdateStatus(status *v1alpha1.QuarantineStatus) {
+	status.Nodes = q.NodeNames()
+	status.Comparisons = q.Comparisons
+	status.Artifacts = q.Artifacts
+	status.VolumeSnapshots = q.VolumeSnapshots
+	status.DegradedWorkloads = q.Degraded
+	status.UnschedulablePods = q.Unschedulable
+	status.Impact = q.Impact
+	status.BlockedPods = []v1alpha1.BlockedPod{}
+
+	waves := []v1alpha1.WaveStatus{}
+
+	for _, n := range q.Nodes {
+		status.BlockedPods = append(status.BlockedPods, n.blocked...)
+		waves = append(waves, n.waves...)
+	}
+
+	// waves are only drained once so the progress is kept until nodes are drained again
+	if len(waves) > 0 {
+		status.Waves = waves
+	}
+
+	if q.Plan != nil {
+		status.Plan = q.Plan.Actions
+	}
+}
+
+func (q *Quarantine) setComparison(comparison v1alpha1.NodeComparison) {
+
+	for i, c := range q.Comparisons {
+		if c.Node == comparison.Node {
+			q.Comparisons[i] = comparison
+			return
+		}
+	}
+
+	q.Comparisons = append(q.Comparisons, comparison)
+}
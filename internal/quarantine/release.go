@@ -0,0 +1,317 @@
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/audit"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+const planActionUntaint = "untaint"
+const planActionUncordon = "uncordon"
+const planActionUnlabel = "unlabel"
+const releaseClusterScope = "cluster"
+
+// Release represents reverting everything a quarantine left behind without a running operator.
+// If nodes is empty every node with quarantine artifacts is released. All reverted actions are returned as plan
+// and every mutation is recorded on the trail.
+func Release(c kubernetes.Interface, nodes []string, dryRun bool, trail *audit.Trail) (*Plan, error) {
+
+	plan := &Plan{
+		Actions: []v1alpha1.PlannedAction{},
+	}
+
+	nodeList, err := c.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return plan, err
+	}
+
+	errs := []error{}
+	found := []string{}
+	stillQuarantined := false
+
+	for _, node := range nodeList.Items {
+
+		if len(nodes) > 0 && !utils.Contains(nodes, node.ObjectMeta.Name) {
+			stillQuarantined = stillQuarantined || HasQuarantineTaint(node)
+			continue
+		}
+
+		found = append(found, node.ObjectMeta.Name)
+
+		if err := releaseNode(c, node, len(nodes) > 0, plan, dryRun, trail); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, name := range nodes {
+		if !utils.Contains(found, name) {
+			errs = append(errs, errors.New("node "+name+" not found"))
+		}
+	}
+
+	if err := releasePods(c, nodes, plan, dryRun, trail); err != nil {
+		errs = append(errs, err)
+	}
+
+	// tolerations are set on workloads and are needed as long as any other node is quarantined
+	if !stillQuarantined {
+		if err := releaseTolerations(c, plan, dryRun, trail); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return plan, utilerrors.NewAggregate(errs)
+}
+
+func releaseNode(c kubernetes.Interface, node corev1.Node, explicit bool, plan *Plan, dryRun bool, trail *audit.Trail) error {
+
+	target := "node/" + node.ObjectMeta.Name
+	tainted := HasQuarantineTaint(node)
+	labels := []string{}
+
+	for k := range node.ObjectMeta.Labels {
+		if strings.HasPrefix(k, QuarantinePodLabelPrefix) {
+			labels = append(labels, k)
+		}
+	}
+
+	sort.Strings(labels)
+
+	// a cordon is only attributed to a quarantine if the node was tainted by it or selected explicitly
+	uncordon := node.Spec.Unschedulable && (tainted || explicit)
+
+	if hasTaint(node, quarantineTaintKey) {
+		plan.add(node.ObjectMeta.Name, planActionUntaint, target, quarantineTaintKey+"="+quarantineTaintValue+":"+quarantineTaintEffect)
+	}
+
+	if hasOutOfServiceTaint(node) {
+		plan.add(node.ObjectMeta.Name, planActionUntaint, target, outOfServiceTaintKey+"="+outOfServiceTaintValue+":"+string(outOfServiceTaintEffect))
+	}
+
+	if uncordon {
+		plan.add(node.ObjectMeta.Name, planActionUncordon, target, "spec.unschedulable=false")
+	}
+
+	for _, k := range labels {
+		plan.add(node.ObjectMeta.Name, planActionUnlabel, target+"/"+k, k+"="+node.ObjectMeta.Labels[k])
+	}
+
+	if dryRun || (!tainted && !uncordon && len(labels) == 0) {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+
+		current, err := c.CoreV1().Nodes().Get(context.TODO(), node.ObjectMeta.Name, metav1.GetOptions{})
+
+		if err != nil {
+			return err
+		}
+
+		before := current.DeepCopy()
+		taints := []corev1.Taint{}
+
+		for _, taint := range current.Spec.Taints {
+			if taint.Key != quarantineTaintKey {
+				taints = append(taints, taint)
+			}
+		}
+
+		current.Spec.Taints = taints
+		withoutOutOfServiceTaint(current)
+
+		if uncordon {
+			current.Spec.Unschedulable = false
+		}
+
+		for _, k := range labels {
+			delete(current.ObjectMeta.Labels, k)
+		}
+
+		updated, err := c.CoreV1().Nodes().Update(context.TODO(), current, metav1.UpdateOptions{})
+
+		if err != nil {
+			return err
+		}
+
+		trail.Add(audit.VerbUpdate, "Node", "", node.ObjectMeta.Name, before, updated)
+		return nil
+	})
+}
+
+func releasePods(c kubernetes.Interface, nodes []string, plan *Plan, dryRun bool, trail *audit.Trail) error {
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: QuarantinePodLabelPrefix + QuarantinePodLabelKey + "=" + quarantinePodLabelValue,
+	}
+
+	pods, err := c.CoreV1().Pods("").List(context.TODO(), listOpts)
+
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+
+	for _, pod := range pods.Items {
+
+		if len(nodes) > 0 && !utils.Contains(nodes, pod.Spec.NodeName) {
+			continue
+		}
+
+		detail := "isolated pod"
+
+		if strings.HasPrefix(pod.ObjectMeta.Name, debugPodName+"-") {
+			detail = "debug pod"
+		}
+
+		plan.add(pod.Spec.NodeName, planActionDelete, podTarget(pod), detail)
+
+		if dryRun {
+			continue
+		}
+
+		if err := c.CoreV1().Pods(pod.ObjectMeta.Namespace).Delete(context.TODO(), pod.ObjectMeta.Name, metav1.DeleteOptions{}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		trail.Add(audit.VerbDelete, "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, &pod, nil)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func releaseTolerations(c kubernetes.Interface, plan *Plan, dryRun bool, trail *audit.Trail) error {
+
+	errs := []error{}
+
+	daemonsets, err := c.AppsV1().DaemonSets("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	for _, ds := range daemonsets.Items {
+
+		if !hasQuarantineToleration(ds.Spec.Template.Spec.Tolerations) {
+			continue
+		}
+
+		plan.add(releaseClusterScope, planActionToleration, dsType+"/"+ds.ObjectMeta.Namespace+"/"+ds.ObjectMeta.Name, "remove "+quarantineTaintKey+":"+quarantineTaintEffect)
+
+		if dryRun {
+			continue
+		}
+
+		if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+
+			current, err := c.AppsV1().DaemonSets(ds.ObjectMeta.Namespace).Get(context.TODO(), ds.ObjectMeta.Name, metav1.GetOptions{})
+
+			if err != nil {
+				return err
+			}
+
+			before := current.DeepCopy()
+			current.Spec.Template.Spec.Tolerations = withoutQuarantineToleration(current.Spec.Template.Spec.Tolerations)
+			updated, err := c.AppsV1().DaemonSets(ds.ObjectMeta.Namespace).Update(context.TODO(), current, metav1.UpdateOptions{})
+
+			if err != nil {
+				return err
+			}
+
+			trail.Add(audit.VerbUpdate, "DaemonSet", ds.ObjectMeta.Namespace, ds.ObjectMeta.Name, before, updated)
+			return nil
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	deployments, err := c.AppsV1().Deployments("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deployments.Items {
+
+		if !hasQuarantineToleration(d.Spec.Template.Spec.Tolerations) {
+			continue
+		}
+
+		plan.add(releaseClusterScope, planActionToleration, deploymentType+"/"+d.ObjectMeta.Namespace+"/"+d.ObjectMeta.Name, "remove "+quarantineTaintKey+":"+quarantineTaintEffect)
+
+		if dryRun {
+			continue
+		}
+
+		if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+
+			current, err := c.AppsV1().Deployments(d.ObjectMeta.Namespace).Get(context.TODO(), d.ObjectMeta.Name, metav1.GetOptions{})
+
+			if err != nil {
+				return err
+			}
+
+			before := current.DeepCopy()
+			current.Spec.Template.Spec.Tolerations = withoutQuarantineToleration(current.Spec.Template.Spec.Tolerations)
+			updated, err := c.AppsV1().Deployments(d.ObjectMeta.Namespace).Update(context.TODO(), current, metav1.UpdateOptions{})
+
+			if err != nil {
+				return err
+			}
+
+			trail.Add(audit.VerbUpdate, "Deployment", d.ObjectMeta.Namespace, d.ObjectMeta.Name, before, updated)
+			return nil
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// HasQuarantineTaint represents checking if a node is tainted by a quarantine, either with the quarantine taint or out of service
+func HasQuarantineTaint(node corev1.Node) bool {
+	return hasTaint(node, quarantineTaintKey) || hasOutOfServiceTaint(node)
+}
+
+func hasTaint(node corev1.Node, key string) bool {
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasQuarantineToleration(tolerations []corev1.Toleration) bool {
+	return len(withoutQuarantineToleration(tolerations)) != len(tolerations)
+}
+
+func withoutQuarantineToleration(tolerations []corev1.Toleration) []corev1.Toleration {
+
+	filtered := []corev1.Toleration{}
+
+	for _, t := range tolerations {
+		if t.Key != quarantineTaintKey {
+			filtered = append(filtered, t)
+		}
+	}
+
+	return filtered
+}
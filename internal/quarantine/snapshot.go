@@ -0,0 +1,239 @@
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/soer3n/incident-operator/internal/audit"
+	"github.com/soer3n/incident-operator/internal/utils"
+)
+
+const snapshotExpiresAnnotation = "expires"
+const snapshotTimeout = 300 * time.Second
+const snapshotInterval = 5 * time.Second
+
+var volumeSnapshotResource = schema.GroupVersionResource{
+	Group:    "snapshot.storage.k8s.io",
+	Version:  "v1",
+	Resource: "volumesnapshots",
+}
+
+// snapshotVolumes creates a volume snapshot for each persistent volume claim of pods which are isolated on a node
+func (q *Quarantine) snapshotVolumes(n *Node) error {
+
+	var dyn dynamic.Interface
+	var claims []string
+	var err error
+
+	if claims, err = n.getIsolatedClaims(); err != nil {
+		return err
+	}
+
+	if n.plan == nil {
+		if dyn, err = n.factory.DynamicClient(); err != nil {
+			return err
+		}
+	}
+
+	for _, claim := range claims {
+
+		namespace, name := splitNamespacedName(claim)
+		snapshotName := truncateName("quarantine-" + q.Name + "-" + name)
+
+		if n.plan != nil {
+			n.plan.add(n.Name, planActionSnapshot, "persistentvolumeclaim/"+claim, "volumesnapshot "+snapshotName)
+			continue
+		}
+
+		n.Logger.Info("snapshot volume...", "pvc", claim, "snapshot", snapshotName)
+
+		if err := q.createSnapshot(dyn, namespace, name, snapshotName); err != nil {
+			return err
+		}
+
+		if err := waitForSnapshot(dyn, namespace, snapshotName, q.Snapshots.Timeout); err != nil {
+			return errors.New("snapshot " + namespace + "/" + snapshotName + " not ready to use: " + err.Error())
+		}
+
+		if key := namespace + "/" + snapshotName; !utils.Contains(q.VolumeSnapshots, key) {
+			q.VolumeSnapshots = append(q.VolumeSnapshots, key)
+		}
+	}
+
+	return nil
+}
+
+func (q *Quarantine) createSnapshot(dyn dynamic.Interface, namespace, claim, name string) error {
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": claim,
+		},
+	}
+
+	if q.Snapshots.VolumeSnapshotClassName != "" {
+		spec["volumeSnapshotClassName"] = q.Snapshots.VolumeSnapshotClassName
+	}
+
+	snapshot := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": volumeSnapshotResource.Group + "/" + volumeSnapshotResource.Version,
+			"kind":       "VolumeSnapshot",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": spec,
+		},
+	}
+
+	snapshot.SetLabels(map[string]string{
+		QuarantinePodLabelPrefix + quarantineNameLabelKey: q.Name,
+	})
+
+	// snapshots have no owner reference so they outlive the quarantine until they expire
+	if q.Snapshots.Retention > 0 {
+		snapshot.SetAnnotations(map[string]string{
+			QuarantinePodLabelPrefix + snapshotExpiresAnnotation: time.Now().Add(q.Snapshots.Retention).UTC().Format(time.RFC3339),
+		})
+	}
+
+	created, err := dyn.Resource(volumeSnapshotResource).Namespace(namespace).Create(context.TODO(), snapshot, metav1.CreateOptions{})
+
+	if k8serrors.IsAlreadyExists(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	q.Audit.Add(audit.VerbCreate, "VolumeSnapshot", namespace, name, nil, created)
+	return nil
+}
+
+func waitForSnapshot(dyn dynamic.Interface, namespace, name string, timeout time.Duration) error {
+
+	return wait.PollImmediate(snapshotInterval, timeout, func() (bool, error) {
+
+		obj, err := dyn.Resource(volumeSnapshotResource).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+
+		if err != nil {
+			return false, nil
+		}
+
+		ready, _, _ := unstructured.NestedBool(obj.Object, "status", "readyToUse")
+
+		return ready, nil
+	})
+}
+
+// PruneSnapshots represents deleting volume snapshots of quarantines whose retention expired
+func PruneSnapshots(dyn dynamic.Interface, trail *audit.Trail, logger logr.Logger) error {
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: QuarantinePodLabelPrefix + quarantineNameLabelKey,
+	}
+
+	snapshots, err := dyn.Resource(volumeSnapshotResource).Namespace("").List(context.TODO(), listOpts)
+
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots.Items {
+
+		expires, ok := snapshot.GetAnnotations()[QuarantinePodLabelPrefix+snapshotExpiresAnnotation]
+
+		if !ok {
+			continue
+		}
+
+		expiresAt, err := time.Parse(time.RFC3339, expires)
+
+		if err != nil || time.Now().Before(expiresAt) {
+			continue
+		}
+
+		if err := dyn.Resource(volumeSnapshotResource).Namespace(snapshot.GetNamespace()).Delete(context.TODO(), snapshot.GetName(), metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+
+		trail.Add(audit.VerbDelete, "VolumeSnapshot", snapshot.GetNamespace(), snapshot.GetName(), &snapshot, nil)
+		logger.Info("expired snapshot deleted", "snapshot", snapshot.GetNamespace()+"/"+snapshot.GetName())
+	}
+
+	return nil
+}
+
+// getIsolatedClaims returns the persistent volume claims of pods on the node which are isolated.
+// This covers pods of listed workloads as well as pods of other workloads like statefulsets isolated by the default strategy.
+func (n *Node) getIsolatedClaims() ([]string, error) {
+
+	claims := []string{}
+
+	pods, err := n.listPods()
+
+	if err != nil {
+		return claims, err
+	}
+
+	workloads, err := n.workloadStrategies()
+
+	if err != nil {
+		return claims, err
+	}
+
+	for _, pod := range pods {
+
+		if excluded, _ := n.Filter.Excludes(pod); excluded {
+			continue
+		}
+
+		if n.strategyFor(pod, workloads) != strategyIsolate {
+			continue
+		}
+
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim == nil {
+				continue
+			}
+
+			if key := pod.ObjectMeta.Namespace + "/" + v.PersistentVolumeClaim.ClaimName; !utils.Contains(claims, key) {
+				claims = append(claims, key)
+			}
+		}
+	}
+
+	return claims, nil
+}
+
+func splitNamespacedName(key string) (string, string) {
+
+	parts := strings.SplitN(key, "/", 2)
+
+	if len(parts) != 2 {
+		return "", key
+	}
+
+	return parts[0], parts[1]
+}
+
+func truncateName(name string) string {
+
+	if len(name) > 253 {
+		return strings.TrimRight(name[:253], "-.")
+	}
+
+	return name
+}
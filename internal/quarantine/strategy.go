@@ -0,0 +1,181 @@
+package quarantine
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubectl/pkg/drain"
+
+	"github.com/soer3n/incident-operator/internal/audit"
+)
+
+// strategies for pods on a quarantined node
+const strategyIsolate = "isolate"
+const strategyEvict = "evict"
+const strategyDelete = "delete"
+const strategyKeep = "keep"
+
+// workloadStrategy represents the strategy for pods selected by a listed workload
+type workloadStrategy struct {
+	namespace string
+	selector  labels.Selector
+	strategy  string
+}
+
+// parseStrategy sets the strategy for pods of unlisted workloads. The node overrides the quarantine.
+func (n *Node) parseStrategy(baseStrategy, nodeStrategy string) {
+
+	n.DefaultStrategy = baseStrategy
+
+	if nodeStrategy != "" {
+		n.DefaultStrategy = nodeStrategy
+	}
+}
+
+// workloadStrategies returns the pod selectors of the listed workloads together with their strategy
+func (n *Node) workloadStrategies() ([]workloadStrategy, error) {
+
+	workloads := []workloadStrategy{}
+	apps := n.Flags.Client.AppsV1()
+
+	for _, ds := range n.Daemonsets {
+
+		obj, err := apps.DaemonSets(ds.Namespace).Get(context.TODO(), ds.Name, metav1.GetOptions{})
+
+		if err != nil {
+			return nil, err
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(obj.Spec.Selector)
+
+		if err != nil {
+			return nil, err
+		}
+
+		workloads = append(workloads, workloadStrategy{namespace: ds.Namespace, selector: selector, strategy: ds.Strategy})
+	}
+
+	for _, d := range n.Deployments {
+
+		obj, err := apps.Deployments(d.Namespace).Get(context.TODO(), d.Name, metav1.GetOptions{})
+
+		if err != nil {
+			return nil, err
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(obj.Spec.Selector)
+
+		if err != nil {
+			return nil, err
+		}
+
+		workloads = append(workloads, workloadStrategy{namespace: d.Namespace, selector: selector, strategy: d.Strategy})
+	}
+
+	return workloads, nil
+}
+
+// selects returns if the pod belongs to the listed workload
+func (w workloadStrategy) selects(pod corev1.Pod) bool {
+	return w.namespace == pod.ObjectMeta.Namespace && !w.selector.Empty() && w.selector.Matches(labels.Set(pod.ObjectMeta.Labels))
+}
+
+// strategyFor returns the strategy for a pod. Isolated pods keep being isolated, pods of listed workloads
+// get the strategy of the workload and all other pods the default strategy of the node.
+// Without a default strategy they are drained and evicted afterwards from isolated nodes only.
+func (n *Node) strategyFor(pod corev1.Pod, workloads []workloadStrategy) string {
+
+	if !podIsNotInQuarantine(pod) {
+		return strategyIsolate
+	}
+
+	if n.plan != nil && n.plan.has(n.Name, planActionRelabel, podTarget(pod)) {
+		return strategyIsolate
+	}
+
+	for _, w := range workloads {
+		if w.selects(pod) {
+			if w.strategy == "" {
+				return strategyIsolate
+			}
+			return w.strategy
+		}
+	}
+
+	if n.DefaultStrategy != "" {
+		return n.DefaultStrategy
+	}
+
+	if n.Isolate {
+		return strategyEvict
+	}
+
+	return ""
+}
+
+// setDrainFilters restricts the drain to pods which are not excluded and should be evicted.
+// Pods with other strategies are handled after the drain.
+func (n *Node) setDrainFilters() error {
+
+	workloads, err := n.workloadStrategies()
+
+	if err != nil {
+		return err
+	}
+
+	n.Flags.AdditionalFilters = []drain.PodFilter{
+		n.Filter.drainFilter,
+		func(pod corev1.Pod) drain.PodDeleteStatus {
+			if strategy := n.strategyFor(pod, workloads); strategy != strategyEvict && strategy != "" {
+				return drain.MakePodDeleteStatusSkip()
+			}
+			return drain.MakePodDeleteStatusOkay()
+		},
+	}
+
+	return nil
+}
+
+// forceDelete represents deleting a pod without grace period and without respecting disruption budgets
+func (n *Node) forceDelete(pod corev1.Pod) error {
+
+	gracePeriod := int64(0)
+	deleteOpts := metav1.DeleteOptions{
+		GracePeriodSeconds: &gracePeriod,
+	}
+
+	err := n.Flags.Client.CoreV1().Pods(pod.ObjectMeta.Namespace).Delete(context.TODO(), pod.ObjectMeta.Name, deleteOpts)
+
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	n.audit.Add(audit.VerbDelete, "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, &pod, nil)
+	n.removed.add(&pod)
+	n.events.emit(&pod, eventReasonDeleted, "pod %s/%s deleted from node %s", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, n.Name)
+
+	return nil
+}
+
+// podWorkload returns the controller of a pod as kind/name which is recorded on isolated pods
+func podWorkload(pod corev1.Pod) string {
+
+	if ref := metav1.GetControllerOf(&pod); ref != nil {
+		return strings.ToLower(ref.Kind) + "/" + ref.Name
+	}
+
+	return "pod/" + pod.ObjectMeta.Name
+}
+
+func isDaemonSetPod(pod corev1.Pod) bool {
+	ref := metav1.GetControllerOf(&pod)
+	return ref != nil && ref.Kind == "DaemonSet"
+}
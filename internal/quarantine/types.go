@@ -1,8 +1,14 @@
 package quarantine
 
 import (
+	"time"
+
 	"github.com/go-logr/logr"
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/artifacts"
+	"github.com/soer3n/incident-operator/internal/audit"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubectl/pkg/cmd/util"
@@ -11,26 +17,79 @@ import (
 
 // Quarantine represents current state of isolation
 type Quarantine struct {
-	Nodes       []*Node
-	MarkedNodes []*Node
-	Debug       Debug
-	Client      kubernetes.Interface
-	isActive    bool
-	Conditions  []metav1.Condition
-	Logger      logr.Logger
+	Name             string
+	Namespace        string
+	Nodes            []*Node
+	MarkedNodes      []*Node
+	Debug            Debug
+	Compare          Compare
+	Logs             Logs
+	Streamer         *LogStreamer
+	Exec             ExecFunc
+	ArtifactSink     *ArtifactSink
+	Evidence         Evidence
+	Snapshots        Snapshots
+	Verification     Verification
+	Capacity         Capacity
+	ImpactAnalysis   ImpactAnalysis
+	DryRun           bool
+	Plan             *Plan
+	Events           *Events
+	Progress         *Progress
+	Audit            *audit.Trail
+	Client           kubernetes.Interface
+	isActive         bool
+	adopted          bool
+	spec             v1alpha1.QuarantineSpec
+	started          []string
+	created          time.Time
+	Conditions       []metav1.Condition
+	Comparisons      []v1alpha1.NodeComparison
+	Artifacts        []string
+	VolumeSnapshots  []string
+	Degraded         []v1alpha1.DegradedWorkload
+	Unschedulable    []v1alpha1.UnschedulablePod
+	Impact           *v1alpha1.Impact
+	removed          *removedWorkloads
+	pendingArtifacts []artifacts.Artifact
+	factory          util.Factory
+	Logger           logr.Logger
 }
 
 // Node represents configuration for isolating a node
 type Node struct {
-	Name        string
-	Debug       Debug
-	Isolate     bool
-	Daemonsets  []Daemonset
-	Deployments []Deployment
-	IOStreams   genericclioptions.IOStreams
-	factory     util.Factory
-	Flags       *drain.Helper
-	Logger      logr.Logger
+	Name                string
+	Debug               Debug
+	Isolate             bool
+	ChunkSize           int64
+	WatchTimeoutSeconds int64
+	Filter              PodFilter
+	DefaultStrategy     string
+	Mode                string
+	SoftCordon          bool
+	Waves               []Wave
+	Daemonsets          []Daemonset
+	Deployments         []Deployment
+	IOStreams           genericclioptions.IOStreams
+	factory             util.Factory
+	plan                *Plan
+	events              *Events
+	progress            *Progress
+	audit               *audit.Trail
+	removed             *removedWorkloads
+	blocked             []v1alpha1.BlockedPod
+	waves               []v1alpha1.WaveStatus
+	Flags               *drain.Helper
+	Logger              logr.Logger
+}
+
+// Wave represents pods which are drained together before the next wave starts
+type Wave struct {
+	Name         string
+	Selector     labels.Selector
+	Pause        time.Duration
+	WaitForReady bool
+	Timeout      time.Duration
 }
 
 // Debug represents a configuration for a debug pod
@@ -40,11 +99,67 @@ type Debug struct {
 	Enabled   bool
 }
 
+// Compare represents a configuration for comparing quarantined nodes against a healthy reference node
+type Compare struct {
+	Enabled       bool
+	ReferenceNode string
+	PoolLabel     string
+	Timeout       time.Duration
+}
+
+// Logs represents a configuration for streaming logs of isolated pods
+type Logs struct {
+	Enabled   bool
+	MaxSizeMB int
+	MaxFiles  int
+}
+
+// ArtifactSink represents a configuration for uploading artifacts to an S3 compatible object storage
+type ArtifactSink struct {
+	Endpoint          string
+	Bucket            string
+	Region            string
+	Prefix            string
+	CredentialsSecret string
+	Insecure          bool
+}
+
+// Evidence represents a configuration for signing the manifest of collected artifacts
+type Evidence struct {
+	SigningKeySecret string
+}
+
+// Snapshots represents a configuration for snapshotting volumes of isolated pods
+type Snapshots struct {
+	Enabled                 bool
+	VolumeSnapshotClassName string
+	Retention               time.Duration
+	Timeout                 time.Duration
+}
+
+// Verification represents a configuration for verifying that workloads of evicted pods are ready again on other nodes
+type Verification struct {
+	Enabled bool
+	Timeout time.Duration
+}
+
+// Capacity represents a configuration for simulating whether pods of quarantined nodes fit on the remaining nodes
+type Capacity struct {
+	Enabled bool
+	Block   bool
+}
+
+// ImpactAnalysis represents a configuration for computing the services, disruption budgets and ingresses affected by a quarantine
+type ImpactAnalysis struct {
+	Enabled bool
+}
+
 // Deployment represents a configuration for a deployment whose pod which is on an affected node should be isolated
 type Deployment struct {
 	Name      string
 	Namespace string
 	Keep      bool
+	Strategy  string
 }
 
 // Daemonset represents a configuration for a daemonset whose pod which is on an affected node should be isolated
@@ -52,6 +167,7 @@ type Daemonset struct {
 	Name      string
 	Namespace string
 	Keep      bool
+	Strategy  string
 }
 
 type tolerationValue struct {
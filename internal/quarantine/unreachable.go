@@ -0,0 +1,207 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/soer3n/incident-operator/internal/metrics"
+)
+
+// the out-of-service taint lets the cluster force delete pods and detach volumes of a node which does not respond anymore
+const outOfServiceTaintKey = "node.kubernetes.io/out-of-service"
+const outOfServiceTaintValue = "nodeshutdown"
+const outOfServiceTaintEffect = corev1.TaintEffectNoExecute
+
+// outOfServiceAnnotation marks nodes whose out-of-service taint was added by a quarantine and is removed on release
+const outOfServiceAnnotation = QuarantinePodLabelPrefix + "out-of-service"
+
+const planActionDetach = "detach"
+const metricsStepUnreachable = "unreachable"
+
+const volumeDetachTimeout = 6 * time.Minute
+const volumeDetachInterval = 5 * time.Second
+
+// removeUnreachable represents moving pods away from a node whose kubelet does not confirm the termination of pods.
+// The node is tainted out of service, pods stuck terminating are force deleted and volume attachments are awaited to be detached.
+func (n *Node) removeUnreachable() error {
+
+	if n.plan == nil {
+		defer metrics.ObserveStep(metricsStepUnreachable, time.Now())
+	}
+
+	n.Logger.Info("taint node out of service...")
+	if err := n.addOutOfServiceTaint(); err != nil {
+		return err
+	}
+
+	n.Logger.Info("force delete terminating pods...")
+	if err := n.deleteTerminatingPods(); err != nil {
+		return err
+	}
+
+	n.Logger.Info("wait for volumes to detach...")
+	return n.waitForDetach()
+}
+
+func (n Node) addOutOfServiceTaint() error {
+
+	nodeObj := n.getNodeAPIObject()
+
+	for _, taint := range nodeObj.Spec.Taints {
+		if taint.Key == outOfServiceTaintKey {
+			return nil
+		}
+	}
+
+	if n.plan != nil {
+		n.plan.add(n.Name, planActionTaint, "node/"+n.Name, outOfServiceTaintKey+"="+outOfServiceTaintValue+":"+string(outOfServiceTaintEffect))
+		return nil
+	}
+
+	before := nodeObj.DeepCopy()
+	nodeObj.Spec.Taints = append(nodeObj.Spec.Taints, corev1.Taint{
+		Key:    outOfServiceTaintKey,
+		Value:  outOfServiceTaintValue,
+		Effect: outOfServiceTaintEffect,
+	})
+
+	if nodeObj.ObjectMeta.Annotations == nil {
+		nodeObj.ObjectMeta.Annotations = map[string]string{}
+	}
+
+	nodeObj.ObjectMeta.Annotations[outOfServiceAnnotation] = quarantineTaintValue
+
+	if err := n.updateNodeAPIObject(before, nodeObj); err != nil {
+		return err
+	}
+
+	n.events.emit(nodeObj, eventReasonOutOfService, "node %s tainted with %s=%s:%s", n.Name, outOfServiceTaintKey, outOfServiceTaintValue, outOfServiceTaintEffect)
+
+	return nil
+}
+
+// deleteTerminatingPods represents force deleting pods whose termination is never confirmed by the kubelet of the node
+func (n *Node) deleteTerminatingPods() error {
+
+	pods, err := n.listPods()
+
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+
+		if pod.ObjectMeta.DeletionTimestamp == nil {
+			continue
+		}
+
+		if n.plan != nil {
+			n.plan.add(n.Name, planActionDelete, podTarget(pod), "terminating on unreachable node")
+			continue
+		}
+
+		if err := n.forceDelete(pod); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// waitForDetach represents waiting until no volume is attached to the node anymore so that pods of statefulsets can start on other nodes
+func (n Node) waitForDetach() error {
+
+	attachments, err := n.volumeAttachments()
+
+	if err != nil {
+		return err
+	}
+
+	if n.plan != nil {
+		for _, va := range attachments {
+			n.plan.add(n.Name, planActionDetach, "volumeattachment/"+va.ObjectMeta.Name, "wait for detach")
+		}
+		return nil
+	}
+
+	timeout := volumeDetachTimeout
+
+	if n.Flags.Timeout > 0 {
+		timeout = n.Flags.Timeout
+	}
+
+	err = wait.PollImmediate(volumeDetachInterval, timeout, func() (bool, error) {
+
+		if attachments, err = n.volumeAttachments(); err != nil {
+			return false, err
+		}
+
+		return len(attachments) == 0, nil
+	})
+
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("%d volumes are still attached to node %s", len(attachments), n.Name)
+	}
+
+	return err
+}
+
+func (n Node) volumeAttachments() ([]storagev1.VolumeAttachment, error) {
+
+	list, err := n.Flags.Client.StorageV1().VolumeAttachments().List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := []storagev1.VolumeAttachment{}
+
+	for _, va := range list.Items {
+		if va.Spec.NodeName == n.Name && va.Status.Attached {
+			attachments = append(attachments, va)
+		}
+	}
+
+	return attachments, nil
+}
+
+// hasOutOfServiceTaint returns if the node is tainted out of service by a quarantine
+func hasOutOfServiceTaint(node corev1.Node) bool {
+
+	if _, ok := node.ObjectMeta.Annotations[outOfServiceAnnotation]; !ok {
+		return false
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == outOfServiceTaintKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withoutOutOfServiceTaint removes the out-of-service taint from the node if it was added by a quarantine
+func withoutOutOfServiceTaint(node *corev1.Node) {
+
+	if _, ok := node.ObjectMeta.Annotations[outOfServiceAnnotation]; !ok {
+		return
+	}
+
+	taints := []corev1.Taint{}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Key != outOfServiceTaintKey {
+			taints = append(taints, taint)
+		}
+	}
+
+	node.Spec.Taints = taints
+	delete(node.ObjectMeta.Annotations, outOfServiceAnnotation)
+}
@@ -0,0 +1,237 @@
+package quarantine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+)
+
+const verificationTimeout = 2 * time.Minute
+const workloadReadyInterval = 2 * time.Second
+
+// workloadRef represents a controller of pods
+type workloadRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// removedWorkloads represents the controllers of pods which were evicted or deleted from quarantined nodes
+type removedWorkloads struct {
+	mu   sync.Mutex
+	refs map[workloadRef]bool
+}
+
+// add records the controller of a removed pod. Pods are removed concurrently by the drain.
+func (w *removedWorkloads) add(pod *corev1.Pod) {
+
+	if w == nil {
+		return
+	}
+
+	ref := metav1.GetControllerOf(pod)
+
+	if ref == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.refs == nil {
+		w.refs = map[workloadRef]bool{}
+	}
+
+	w.refs[workloadRef{Kind: ref.Kind, Namespace: pod.ObjectMeta.Namespace, Name: ref.Name}] = true
+}
+
+func (w *removedWorkloads) list() []workloadRef {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	refs := []workloadRef{}
+
+	for ref := range w.refs {
+		refs = append(refs, ref)
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Namespace+"/"+refs[i].Kind+"/"+refs[i].Name < refs[j].Namespace+"/"+refs[j].Kind+"/"+refs[j].Name
+	})
+
+	return refs
+}
+
+// verify represents waiting until the workloads of evicted and deleted pods have all replicas ready again on other nodes.
+// Workloads which are not ready within the timeout are recorded as degraded.
+func (q *Quarantine) verify() error {
+
+	refs := []workloadRef{}
+	seen := map[workloadRef]bool{}
+
+	for _, ref := range q.removed.list() {
+
+		owner, err := workloadOwner(q.Client, ref)
+
+		if err != nil {
+			return err
+		}
+
+		if !seen[owner] {
+			seen[owner] = true
+			refs = append(refs, owner)
+		}
+	}
+
+	q.Logger.Info("verify workloads of evicted pods...", "workloads", len(refs))
+
+	err := wait.PollImmediate(workloadReadyInterval, q.Verification.Timeout, func() (bool, error) {
+
+		degraded, err := degradedWorkloads(q.Client, refs)
+
+		if err != nil {
+			return false, err
+		}
+
+		q.Degraded = degraded
+
+		return len(degraded) == 0, nil
+	})
+
+	if err != nil && err != wait.ErrWaitTimeout {
+		return err
+	}
+
+	return nil
+}
+
+// recheck represents checking workloads which were degraded once more without waiting
+func (q *Quarantine) recheck() error {
+
+	refs := []workloadRef{}
+
+	for _, w := range q.Degraded {
+		refs = append(refs, workloadRef{Kind: w.Kind, Namespace: w.Namespace, Name: w.Name})
+	}
+
+	degraded, err := degradedWorkloads(q.Client, refs)
+
+	if err != nil {
+		return err
+	}
+
+	q.Degraded = degraded
+
+	return nil
+}
+
+// IsDegraded represents returning if workloads of evicted pods are not ready again
+func (q Quarantine) IsDegraded() bool {
+	return len(q.Degraded) > 0
+}
+
+func degradedWorkloads(c kubernetes.Interface, refs []workloadRef) ([]v1alpha1.DegradedWorkload, error) {
+
+	degraded := []v1alpha1.DegradedWorkload{}
+
+	for _, ref := range refs {
+
+		replicas, ready, err := workloadReplicas(c, ref)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if ready < replicas {
+			degraded = append(degraded, v1alpha1.DegradedWorkload{
+				Kind:          ref.Kind,
+				Namespace:     ref.Namespace,
+				Name:          ref.Name,
+				Replicas:      replicas,
+				ReadyReplicas: ready,
+			})
+		}
+	}
+
+	return degraded, nil
+}
+
+// workloadOwner returns the deployment of a replicaset or the workload itself
+func workloadOwner(c kubernetes.Interface, ref workloadRef) (workloadRef, error) {
+
+	if ref.Kind != "ReplicaSet" {
+		return ref, nil
+	}
+
+	rs, err := c.AppsV1().ReplicaSets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+
+	if apierrors.IsNotFound(err) {
+		return ref, nil
+	}
+
+	if err != nil {
+		return ref, err
+	}
+
+	if owner := metav1.GetControllerOf(rs); owner != nil && owner.Kind == "Deployment" {
+		return workloadRef{Kind: owner.Kind, Namespace: ref.Namespace, Name: owner.Name}, nil
+	}
+
+	return ref, nil
+}
+
+// workloadReplicas returns the desired and ready replicas of a workload.
+// Workloads which do not exist anymore or are not scaled by replicas have nothing to wait for.
+func workloadReplicas(c kubernetes.Interface, ref workloadRef) (int32, int32, error) {
+
+	var replicas *int32
+	var ready int32
+	var err error
+
+	apps := c.AppsV1()
+
+	switch ref.Kind {
+	case "Deployment":
+		var d *v1.Deployment
+		if d, err = apps.Deployments(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{}); err == nil {
+			replicas, ready = d.Spec.Replicas, d.Status.ReadyReplicas
+		}
+	case "ReplicaSet":
+		var rs *v1.ReplicaSet
+		if rs, err = apps.ReplicaSets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{}); err == nil {
+			replicas, ready = rs.Spec.Replicas, rs.Status.ReadyReplicas
+		}
+	case "StatefulSet":
+		var sts *v1.StatefulSet
+		if sts, err = apps.StatefulSets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{}); err == nil {
+			replicas, ready = sts.Spec.Replicas, sts.Status.ReadyReplicas
+		}
+	default:
+		return 0, 0, nil
+	}
+
+	if apierrors.IsNotFound(err) {
+		return 0, 0, nil
+	}
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if replicas == nil {
+		return 1, ready, nil
+	}
+
+	return *replicas, ready, nil
+}
@@ -0,0 +1,235 @@
+package quarantine
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubectl/pkg/drain"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+)
+
+const wavePhaseDraining = "Draining"
+const wavePhaseWaiting = "Waiting"
+const wavePhaseCompleted = "Completed"
+const wavePhaseFailed = "Failed"
+
+const waveReadyTimeout = 5 * time.Minute
+
+// waveMaxPause limits the pause after a wave since the reconcile of the quarantine is blocked meanwhile
+const waveMaxPause = 5 * time.Minute
+
+// Progress represents reporting the waves of all nodes while they are drained so that the current phase is visible in the status
+type Progress struct {
+	Report func(waves []v1alpha1.WaveStatus)
+	nodes  []*Node
+}
+
+// report passes the current waves of all nodes to the reporter if it is set
+func (p *Progress) report() {
+
+	if p == nil || p.Report == nil {
+		return
+	}
+
+	waves := []v1alpha1.WaveStatus{}
+
+	for _, n := range p.nodes {
+		waves = append(waves, n.waves...)
+	}
+
+	p.Report(waves)
+}
+
+// parseWaves converts the drain waves of the quarantine for the node
+func (n *Node) parseWaves(waves []v1alpha1.Wave) error {
+
+	n.Waves = []Wave{}
+
+	for _, w := range waves {
+
+		w := w
+		selector, err := metav1.LabelSelectorAsSelector(&w.Selector)
+
+		if err != nil {
+			return err
+		}
+
+		pause := time.Duration(w.PauseSeconds) * time.Second
+
+		if pause > waveMaxPause {
+			pause = waveMaxPause
+		}
+
+		n.Waves = append(n.Waves, Wave{
+			Name:         w.Name,
+			Selector:     selector,
+			Pause:        pause,
+			WaitForReady: w.WaitForReady,
+			Timeout:      time.Duration(w.TimeoutSeconds) * time.Second,
+		})
+	}
+
+	return nil
+}
+
+// waveIndex returns the first wave selecting the pod or -1 if the pod is drained after all waves
+func (n Node) waveIndex(pod corev1.Pod) int {
+
+	for i, w := range n.Waves {
+		if !w.Selector.Empty() && w.Selector.Matches(labels.Set(pod.ObjectMeta.Labels)) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// drainWaves represents draining the node wave by wave. Pods which are not selected by any wave are drained last.
+func (n *Node) drainWaves() error {
+
+	base := n.Flags.AdditionalFilters
+
+	defer func() {
+		n.Flags.AdditionalFilters = base
+	}()
+
+	n.waves = []v1alpha1.WaveStatus{}
+
+	for i := range n.Waves {
+
+		index := i
+		n.Flags.AdditionalFilters = append(append([]drain.PodFilter{}, base...), func(pod corev1.Pod) drain.PodDeleteStatus {
+			if n.waveIndex(pod) != index {
+				return drain.MakePodDeleteStatusSkip()
+			}
+			return drain.MakePodDeleteStatusOkay()
+		})
+
+		if err := n.drainWave(n.Waves[index]); err != nil {
+			return err
+		}
+	}
+
+	n.Flags.AdditionalFilters = base
+
+	_, err := n.drainPods()
+	return err
+}
+
+func (n *Node) drainWave(w Wave) error {
+
+	status := v1alpha1.WaveStatus{
+		Node:  n.Name,
+		Name:  w.Name,
+		Phase: wavePhaseDraining,
+	}
+
+	n.Logger.Info("drain wave...", "wave", w.Name)
+
+	list, errs := n.Flags.GetPodsForDeletion(n.Name)
+
+	if errs != nil {
+		return n.failWave(status, utilerrors.NewAggregate(errs))
+	}
+
+	pods := list.Pods()
+	status.Pods = len(pods)
+	n.setWave(status)
+
+	// blocked pods are recorded and do not stop the following waves
+	if err := n.evict(pods); err != nil {
+		return n.failWave(status, err)
+	}
+
+	n.waitForDeletion(pods)
+
+	if w.Pause > 0 || w.WaitForReady {
+		status.Phase = wavePhaseWaiting
+		n.setWave(status)
+	}
+
+	if w.Pause > 0 {
+		n.Logger.Info("pause after wave...", "wave", w.Name, "pause", w.Pause.String())
+		time.Sleep(w.Pause)
+	}
+
+	if w.WaitForReady {
+		if err := n.waitForWorkloads(pods, n.waveTimeout(w)); err != nil {
+			return n.failWave(status, fmt.Errorf("workloads of drained pods are not ready: %w", err))
+		}
+	}
+
+	status.Phase = wavePhaseCompleted
+	n.setWave(status)
+
+	return nil
+}
+
+func (n *Node) failWave(status v1alpha1.WaveStatus, err error) error {
+
+	status.Phase = wavePhaseFailed
+	status.Message = err.Error()
+	n.setWave(status)
+
+	return fmt.Errorf("wave %s on node %s failed: %w", status.Name, n.Name, err)
+}
+
+// setWave records the current phase of a wave and reports the progress
+func (n *Node) setWave(status v1alpha1.WaveStatus) {
+
+	defer n.progress.report()
+
+	for i, w := range n.waves {
+		if w.Name == status.Name {
+			n.waves[i] = status
+			return
+		}
+	}
+
+	n.waves = append(n.waves, status)
+}
+
+func (n Node) waveTimeout(w Wave) time.Duration {
+
+	if w.Timeout > 0 {
+		return w.Timeout
+	}
+
+	if n.Flags.Timeout > 0 {
+		return n.Flags.Timeout
+	}
+
+	return waveReadyTimeout
+}
+
+// waitForWorkloads represents waiting until the replicasets and statefulsets of the pods have all replicas ready
+func (n Node) waitForWorkloads(pods []corev1.Pod, timeout time.Duration) error {
+
+	refs := map[workloadRef]bool{}
+
+	for _, pod := range pods {
+		if ref := metav1.GetControllerOf(&pod); ref != nil {
+			refs[workloadRef{Kind: ref.Kind, Namespace: pod.ObjectMeta.Namespace, Name: ref.Name}] = true
+		}
+	}
+
+	return wait.PollImmediate(workloadReadyInterval, timeout, func() (bool, error) {
+
+		for ref := range refs {
+
+			replicas, ready, err := workloadReplicas(n.Flags.Client, ref)
+
+			if err != nil || ready < replicas {
+				return false, err
+			}
+		}
+
+		return true, nil
+	})
+}
@@ -0,0 +1,144 @@
+package safety
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+)
+
+// DefaultMaxUnschedulablePercent represents the share of nodes which may be unschedulable if the quarantine does not set one
+const DefaultMaxUnschedulablePercent = 50
+
+// DefaultTopologyLabel represents the label grouping nodes if the quarantine does not set topology labels
+const DefaultTopologyLabel = "topology.kubernetes.io/zone"
+
+// nodes in soft mode keep being schedulable unless they are cordoned explicitly
+const modeSoft = "soft"
+
+var controlPlaneLabels = []string{
+	"node-role.kubernetes.io/control-plane",
+	"node-role.kubernetes.io/master",
+}
+
+// Check represents validating a quarantine against the nodes of the cluster.
+// It returns an error listing all violated guards if the quarantine would touch control plane nodes without allowing it,
+// exceed the share of unschedulable nodes or cordon the last schedulable node of a zone or pool.
+func Check(spec v1alpha1.QuarantineSpec, nodes []corev1.Node) error {
+
+	errs := []error{}
+	cordoned := map[string]bool{}
+	selected := map[string]bool{}
+
+	for _, n := range spec.Nodes {
+		selected[n.Name] = true
+
+		if cordons(spec, n) {
+			cordoned[n.Name] = true
+		}
+	}
+
+	unschedulable := 0
+
+	for _, node := range nodes {
+
+		if selected[node.ObjectMeta.Name] && !spec.Safety.AllowControlPlane && IsControlPlane(node) {
+			errs = append(errs, fmt.Errorf("node %s is a control plane node", node.ObjectMeta.Name))
+		}
+
+		if node.Spec.Unschedulable || cordoned[node.ObjectMeta.Name] {
+			unschedulable++
+		}
+	}
+
+	max := DefaultMaxUnschedulablePercent
+
+	if spec.Safety.MaxUnschedulablePercent != nil {
+		max = *spec.Safety.MaxUnschedulablePercent
+	}
+
+	if len(nodes) > 0 && len(cordoned) > 0 && unschedulable*100 > max*len(nodes) {
+		errs = append(errs, fmt.Errorf("%d of %d nodes would be unschedulable which exceeds %d%%", unschedulable, len(nodes), max))
+	}
+
+	labels := spec.Safety.TopologyLabels
+
+	if len(labels) == 0 {
+		labels = []string{DefaultTopologyLabel}
+	}
+
+	for _, label := range labels {
+		errs = append(errs, lastSchedulable(label, nodes, cordoned)...)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// IsControlPlane returns if the node is labeled as a control plane node
+func IsControlPlane(node corev1.Node) bool {
+
+	for _, label := range controlPlaneLabels {
+		if _, ok := node.ObjectMeta.Labels[label]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cordons returns if the node is marked unschedulable by the quarantine
+func cordons(spec v1alpha1.QuarantineSpec, n v1alpha1.Node) bool {
+
+	mode := spec.Mode
+
+	if n.Mode != "" {
+		mode = n.Mode
+	}
+
+	return mode != modeSoft || spec.Soft.Cordon
+}
+
+// lastSchedulable represents finding groups of nodes sharing a label value whose last schedulable node would be cordoned
+func lastSchedulable(label string, nodes []corev1.Node, cordoned map[string]bool) []error {
+
+	schedulable := map[string]int{}
+	touched := map[string]bool{}
+
+	for _, node := range nodes {
+
+		value, ok := node.ObjectMeta.Labels[label]
+
+		if !ok {
+			continue
+		}
+
+		if cordoned[node.ObjectMeta.Name] {
+			touched[value] = true
+			continue
+		}
+
+		if !node.Spec.Unschedulable {
+			schedulable[value]++
+		}
+	}
+
+	values := []string{}
+
+	for value := range touched {
+		if schedulable[value] == 0 {
+			values = append(values, value)
+		}
+	}
+
+	sort.Strings(values)
+	errs := []error{}
+
+	for _, value := range values {
+		errs = append(errs, fmt.Errorf("no schedulable node would be left with %s=%s", label, value))
+	}
+
+	return errs
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	evictionKind        = "Eviction"
+	evictionSubresource = "pods/eviction"
+)
+
+// SupportEviction represents discovering the policy group version of the eviction api.
+// An empty version is returned if the cluster does not support evictions.
+func SupportEviction(client discovery.ServerResourcesInterface) (string, error) {
+	groupList, _, err := client.ServerGroupsAndResources()
+	if err != nil {
+		return "", err
+	}
+	foundPolicyGroup := false
+	var policyGroupVersion string
+	for _, group := range groupList {
+		if group.Name == "policy" {
+			foundPolicyGroup = true
+			policyGroupVersion = group.PreferredVersion.GroupVersion
+			break
+		}
+	}
+	if !foundPolicyGroup {
+		return "", nil
+	}
+	resourceList, err := client.ServerResourcesForGroupVersion("v1")
+	if err != nil {
+		return "", err
+	}
+	for _, resource := range resourceList.APIResources {
+		if resource.Name == evictionSubresource && resource.Kind == evictionKind {
+			return policyGroupVersion, nil
+		}
+	}
+	return "", nil
+}
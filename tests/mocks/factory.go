@@ -1,6 +1,9 @@
 package mocks
 
-import "k8s.io/client-go/kubernetes"
+import (
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
 
 // KuerbetesClientSet represents mock func for similar for getting clientset
 func (c *K8SFactoryMock) KubernetesClientSet() (*kubernetes.Clientset, error) {
@@ -9,3 +12,11 @@ func (c *K8SFactoryMock) KubernetesClientSet() (*kubernetes.Clientset, error) {
 	err := args.Error(1)
 	return kc, err
 }
+
+// DynamicClient represents mock func for getting a dynamic client
+func (c *K8SFactoryMock) DynamicClient() (dynamic.Interface, error) {
+	args := c.Called()
+	dc := args.Get(0).(dynamic.Interface)
+	err := args.Error(1)
+	return dc, err
+}
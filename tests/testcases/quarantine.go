@@ -323,7 +323,7 @@ func GetQuarantinePrepareStructs() []tests.QuarantineTestCase {
 				},
 				{
 					Resource: TestClientResource{
-						Name: "quarantine-debug", Node: "foo", Isolated: false, Watch: true, Taint: false, ListSelector: []string{"foo=bar"}, FieldSelector: []string{"spec.nodeName=bar"}},
+						Name: "quarantine-debug-foo", Node: "foo", Isolated: false, Watch: true, Taint: false, ListSelector: []string{"foo=bar"}, FieldSelector: []string{"spec.nodeName=bar"}},
 				},
 				{
 					Resource: TestClientResource{
@@ -376,7 +376,8 @@ func GetQuarantinePrepareStructs() []tests.QuarantineTestCase {
 							Keep:      true,
 						},
 					},
-					Logger: ctrl.Log.WithName("test"),
+					Logger:              ctrl.Log.WithName("test"),
+					WatchTimeoutSeconds: 20,
 					Flags: &drain.Helper{
 						Client:              c.FakeClient,
 						IgnoreAllDaemonSets: true,
@@ -396,11 +397,12 @@ func GetQuarantinePrepareStructs() []tests.QuarantineTestCase {
 					},
 				},
 				{
-					Name:        "bar",
-					Isolate:     false,
-					Daemonsets:  []q.Daemonset{},
-					Deployments: []q.Deployment{},
-					Logger:      ctrl.Log.WithName("test"),
+					Name:                "bar",
+					Isolate:             false,
+					Daemonsets:          []q.Daemonset{},
+					Deployments:         []q.Deployment{},
+					Logger:              ctrl.Log.WithName("test"),
+					WatchTimeoutSeconds: 20,
 					Flags: &drain.Helper{
 						Client:              c.FakeClient,
 						IgnoreAllDaemonSets: true,
@@ -435,11 +437,12 @@ func GetQuarantinePrepareStructs() []tests.QuarantineTestCase {
 		Input: &q.Quarantine{
 			Nodes: []*q.Node{
 				{
-					Name:        "foo",
-					Isolate:     true,
-					Daemonsets:  []q.Daemonset{},
-					Deployments: []q.Deployment{},
-					Logger:      ctrl.Log.WithName("test"),
+					Name:                "foo",
+					Isolate:             true,
+					Daemonsets:          []q.Daemonset{},
+					Deployments:         []q.Deployment{},
+					Logger:              ctrl.Log.WithName("test"),
+					WatchTimeoutSeconds: 20,
 					Flags: &drain.Helper{
 						Client:              c.FakeClient,
 						IgnoreAllDaemonSets: true,
@@ -459,11 +462,12 @@ func GetQuarantinePrepareStructs() []tests.QuarantineTestCase {
 					},
 				},
 				{
-					Name:        "bar",
-					Isolate:     true,
-					Daemonsets:  []q.Daemonset{},
-					Deployments: []q.Deployment{},
-					Logger:      ctrl.Log.WithName("test"),
+					Name:                "bar",
+					Isolate:             true,
+					Daemonsets:          []q.Daemonset{},
+					Deployments:         []q.Deployment{},
+					Logger:              ctrl.Log.WithName("test"),
+					WatchTimeoutSeconds: 20,
 					Flags: &drain.Helper{
 						Client:              c.FakeClient,
 						IgnoreAllDaemonSets: true,
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/soer3n/incident-operator/internal/artifacts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadArtifact(t *testing.T) {
+
+	var method, path, auth, body string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := ioutil.ReadAll(r.Body)
+		method, path, auth, body = r.Method, r.URL.EscapedPath(), r.Header.Get("Authorization"), string(content)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := artifacts.NewS3Sink(server.URL, "evidence", "", "minio", "minio123", false)
+	err := sink.Upload(context.TODO(), "default/sample/20210101T000000Z/logs/foo bar.log", []byte("foo"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal(http.MethodPut, method)
+	assert.Equal("/evidence/default/sample/20210101T000000Z/logs/foo%20bar.log", path)
+	assert.True(strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=minio/"))
+	assert.Contains(auth, "/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=")
+	assert.Equal("foo", body)
+}
+
+func TestUploadArtifactDenied(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sink := artifacts.NewS3Sink(server.URL, "evidence", "eu-central-1", "minio", "wrong", false)
+	err := sink.Upload(context.TODO(), "foo", []byte("foo"))
+
+	assert := assert.New(t)
+	assert.NotNil(err)
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/soer3n/incident-operator/internal/audit"
+	"github.com/soer3n/incident-operator/internal/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditDiff(t *testing.T) {
+
+	assert := assert.New(t)
+
+	before := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker1"},
+	}
+
+	after := before.DeepCopy()
+	after.Spec.Unschedulable = true
+
+	diff, err := audit.Diff(before, after)
+	assert.Nil(err)
+	assert.JSONEq(`{"spec":{"unschedulable":true}}`, string(diff))
+
+	revert, err := audit.Diff(after, before)
+	assert.Nil(err)
+	assert.JSONEq(`{"spec":{"unschedulable":null}}`, string(revert))
+
+	// removed objects keep their full state in the revert patch
+	var nilNode *corev1.Node
+	diff, err = audit.Diff(before, nilNode)
+	assert.Nil(err)
+	assert.Equal("null", string(diff))
+
+	revert, err = audit.Diff(nilNode, before)
+	assert.Nil(err)
+	assert.Contains(string(revert), `"name":"worker1"`)
+}
+
+func TestAuditTrail(t *testing.T) {
+
+	assert := assert.New(t)
+	fakeClientset := fake.NewSimpleClientset()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "promtail-abc", Namespace: "default"},
+	}
+
+	trail := audit.NewTrail("default/test")
+	trail.Add(audit.VerbEvict, "Pod", "default", "promtail-abc", pod, nil)
+	assert.Nil(trail.Flush(fakeClientset, "default", "test"))
+	assert.Empty(trail.Pending())
+
+	// records which do not fit into the current config map start a new segment
+	large := pod.DeepCopy()
+	large.ObjectMeta.Annotations = map[string]string{"payload": strings.Repeat("x", audit.MaxSegmentBytes/3)}
+
+	trail = audit.NewTrail("default/test")
+
+	for i := 0; i < 3; i++ {
+		trail.Add(audit.VerbUpdate, "Pod", "default", "promtail-abc", pod, large)
+	}
+
+	assert.Nil(trail.Flush(fakeClientset, "default", "test"))
+
+	segments, err := fakeClientset.CoreV1().ConfigMaps("default").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(err)
+	assert.Len(segments.Items, 2)
+
+	for _, cm := range segments.Items {
+		if cm.ObjectMeta.Name == audit.ConfigMapPrefix+"test-0" {
+			assert.True(*cm.Immutable)
+		}
+	}
+
+	records, err := audit.Load(fakeClientset, "default", "test")
+	assert.Nil(err)
+	assert.Len(records, 4)
+
+	for i, r := range records {
+		assert.Equal(i, r.Sequence)
+		assert.Equal("default/test", r.Actor)
+	}
+
+	assert.Equal(audit.VerbEvict, records[0].Verb)
+
+	var buf bytes.Buffer
+	assert.Nil(cli.WriteAudit(&buf, fakeClientset, "default", "test", "jsonl"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(lines, 4)
+
+	first := audit.Record{}
+	assert.Nil(json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal("promtail-abc", first.Name)
+
+	assert.NotNil(cli.WriteAudit(&buf, fakeClientset, "default", "test", "yaml"))
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func capacityNode(name, zone, cpu string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"topology.kubernetes.io/zone": zone}},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+				corev1.ResourcePods:   resource.MustParse("10"),
+			},
+		},
+	}
+}
+
+func capacityPod(name, cpu string, volumes ...corev1.Volume) *corev1.Pod {
+
+	controller := true
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: name + "-rs", Controller: &controller},
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "worker1",
+			Containers: []corev1.Container{
+				{Name: name, Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+				}},
+			},
+			Volumes: volumes,
+		},
+	}
+}
+
+func capacityObjects() []runtime.Object {
+
+	claim := corev1.Volume{
+		Name:         "data",
+		VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db"}},
+	}
+
+	return []runtime.Object{
+		capacityNode("worker1", "a", "4"),
+		capacityNode("worker2", "a", "1"),
+		capacityNode("worker3", "b", "4", corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}),
+		capacityPod("web", "500m"),
+		capacityPod("grafana", "2"),
+		capacityPod("db", "100m", claim),
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data-db", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-db"},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-db", Labels: map[string]string{"topology.kubernetes.io/zone": "b"}},
+		},
+	}
+}
+
+func TestPlanCapacity(t *testing.T) {
+
+	fakeClientset := fake.NewSimpleClientset(capacityObjects()...)
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun:   true,
+			Capacity: v1alpha1.Capacity{Enabled: true, Block: true},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	// a dry run is not blocked but reports pods which would not fit
+	assert.Nil(q.Prepare())
+
+	status := &v1alpha1.QuarantineStatus{}
+	q.UpdateStatus(status)
+
+	assert.Equal([]v1alpha1.UnschedulablePod{
+		{Node: "worker1", Pod: "default/db", Reason: "0/2 remaining nodes are available: 1 node(s) had untolerated taint, 1 node(s) had volume node affinity conflict"},
+		{Node: "worker1", Pod: "default/grafana", Reason: "0/2 remaining nodes are available: 1 Insufficient cpu, 1 node(s) had untolerated taint"},
+	}, status.UnschedulablePods)
+
+	kinds := map[string]string{}
+
+	for _, a := range q.Plan.Actions {
+		kinds[a.Kind+" "+a.Target] = a.Detail
+	}
+
+	assert.Contains(kinds, "unschedulable pod/default/grafana")
+	assert.Contains(kinds, "unschedulable pod/default/db")
+	assert.NotContains(kinds, "unschedulable pod/default/web")
+}
+
+func TestBlockCapacity(t *testing.T) {
+
+	fakeClientset := fake.NewSimpleClientset(capacityObjects()...)
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Capacity: v1alpha1.Capacity{Enabled: true, Block: true},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	err = q.Prepare()
+	assert.EqualError(err, "2 pods would not fit on the remaining nodes")
+
+	var blocked *quarantine.BlockedError
+	assert.True(errors.As(err, &blocked))
+	assert.Equal(quarantine.BlockedReasonCapacity, blocked.Reason)
+
+	// the node is not touched if the quarantine is blocked
+	current, _ := fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.False(current.Spec.Unschedulable)
+
+	// a blocked quarantine is prepared again instead of being updated
+	spec.Status.Conditions = []metav1.Condition{{Type: "active", Status: metav1.ConditionFalse, Reason: "capacity", Message: err.Error()}}
+
+	q, err = quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+	assert.Nil(err)
+	assert.False(q.IsActive())
+
+	// nodes added to a running quarantine are blocked as well
+	spec.Status.Conditions = []metav1.Condition{{Type: "active", Status: metav1.ConditionTrue, Reason: "running", Message: "success"}}
+
+	q, err = quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+	assert.Nil(err)
+	assert.True(q.IsActive())
+	assert.EqualError(q.Update(), "2 pods would not fit on the remaining nodes")
+
+	current, _ = fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.False(current.Spec.Unschedulable)
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/cli"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePlan(t *testing.T) {
+
+	actions := []v1alpha1.PlannedAction{
+		{Node: "worker2", Kind: "cordon", Target: "node/worker2", Detail: "spec.unschedulable=true"},
+		{Node: "worker1", Kind: "evict", Target: "pod/default/web", Detail: "drain"},
+		{Node: "worker1", Kind: "blocker", Target: "pod/default/web", Detail: "a|b"},
+	}
+
+	assert := assert.New(t)
+	out := &bytes.Buffer{}
+
+	assert.Nil(cli.WritePlan(out, actions, "table"))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(lines, 4)
+	assert.True(strings.HasPrefix(lines[1], "worker1"))
+	assert.True(strings.HasPrefix(lines[3], "worker2"))
+
+	out.Reset()
+	assert.Nil(cli.WritePlan(out, actions, "json"))
+	nodes := map[string][]v1alpha1.PlannedAction{}
+	assert.Nil(json.Unmarshal(out.Bytes(), &nodes))
+	assert.Len(nodes["worker1"], 2)
+	assert.Len(nodes["worker2"], 1)
+
+	out.Reset()
+	assert.Nil(cli.WritePlan(out, actions, "markdown"))
+	assert.Contains(out.String(), "### worker1")
+	assert.Contains(out.String(), "### worker2")
+	assert.Contains(out.String(), "| blocker | pod/default/web | a\\|b |")
+
+	assert.NotNil(cli.WritePlan(out, actions, "yaml"))
+}
+
+func TestPreviewQuarantine(t *testing.T) {
+
+	fakeClientset := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master1", Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker2"}},
+	)
+
+	assert := assert.New(t)
+	out := &bytes.Buffer{}
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Nodes: []v1alpha1.Node{{Name: "worker1", Isolate: true}},
+		},
+	}
+
+	// the preview is always a dry run which does not touch the node
+	assert.Nil(cli.PreviewQuarantine(out, spec, fakeClientset, &mocks.K8SFactoryMock{}, "table"))
+	assert.Contains(out.String(), "cordon")
+
+	node, err := fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.False(node.Spec.Unschedulable)
+
+	// a quarantine the controller would pause is not planned
+	out.Reset()
+	spec = &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Nodes: []v1alpha1.Node{{Name: "master1"}},
+		},
+	}
+
+	err = cli.PreviewQuarantine(out, spec, fakeClientset, &mocks.K8SFactoryMock{}, "table")
+	assert.EqualError(err, "quarantine violates cluster safety guards: node master1 is a control plane node")
+	assert.Empty(out.String())
+}
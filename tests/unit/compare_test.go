@@ -0,0 +1,282 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/evidence"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func compareNode(name, pool string, ready bool) *corev1.Node {
+
+	status := corev1.ConditionTrue
+
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"node.kubernetes.io/instance-type": pool}},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+// compareClientset returns a clientset whose debug pods are in the phase as soon as they are created
+func compareClientset(phase corev1.PodPhase, objects ...runtime.Object) *fake.Clientset {
+
+	fakeClientset := fake.NewSimpleClientset(objects...)
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(&corev1.Node{})
+		return true, w, nil
+	})
+
+	fakeClientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.Status.Phase = phase
+		return false, nil, nil
+	})
+
+	return fakeClientset
+}
+
+// compareExec returns the output of the collectors for the debug pod of a node
+func compareExec(outputs map[string]map[string]string) quarantine.ExecFunc {
+	return func(namespace, name, container string, command []string) (string, error) {
+
+		node := strings.TrimPrefix(name, "quarantine-debug-")
+		script := command[len(command)-1]
+
+		for prefix, out := range outputs[node] {
+			if strings.HasPrefix(script, prefix) {
+				return out, nil
+			}
+		}
+
+		return "", fmt.Errorf("command not found")
+	}
+}
+
+func TestCompareNodes(t *testing.T) {
+
+	fakeClientset := compareClientset(
+		corev1.PodRunning,
+		compareNode("worker1", "m5.large", true),
+		compareNode("worker2", "m5.large", false),
+		compareNode("worker3", "m5.large", true),
+		compareNode("worker4", "c5.large", true),
+	)
+
+	modules := []string{}
+
+	for i := 0; i < 30; i++ {
+		modules = append(modules, fmt.Sprintf("mod%02d", i))
+	}
+
+	outputs := map[string]map[string]string{
+		"worker1": {
+			"uname":  "5.10.0-1\n",
+			"sysctl": "vm.swappiness = 60\nkernel.random.boot_id = abc\nnet.ipv4.ip_forward = 1\n",
+			"cut":    strings.Join(modules, "\n"),
+			"awk":    "/ rootfs\n/sys sysfs\n",
+			"rules=": "filter 120\nnat 45\n",
+		},
+		"worker3": {
+			"uname":  "5.10.0-2\n",
+			"sysctl": "vm.swappiness = 10\nkernel.random.boot_id = def\nnet.ipv4.ip_forward = 1\n",
+			"cut":    "",
+			"awk":    "/ rootfs\n/sys sysfs\n",
+			"rules=": "filter 118\nnat 45\n",
+		},
+	}
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Compare: v1alpha1.Compare{Enabled: true},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	q.Exec = compareExec(outputs)
+	assert.Nil(q.Prepare())
+
+	status := &v1alpha1.QuarantineStatus{}
+	q.UpdateStatus(status)
+
+	assert.Len(status.Comparisons, 1)
+
+	comparison := status.Comparisons[0]
+
+	// the unhealthy node of the pool is skipped
+	assert.Equal("worker3", comparison.ReferenceNode)
+
+	// the debug pods are removed after the comparison
+	pods, _ := fakeClientset.CoreV1().Pods("kube-system").List(context.TODO(), metav1.ListOptions{})
+	assert.Empty(pods.Items)
+
+	// the debug pods can read the iptables rules without being privileged
+	for _, action := range fakeClientset.Actions() {
+		if create, ok := action.(k8stesting.CreateAction); ok && action.GetResource().Resource == "pods" {
+			container := create.GetObject().(*corev1.Pod).Spec.Containers[0]
+			assert.Nil(container.SecurityContext.Privileged)
+			assert.Equal([]corev1.Capability{"NET_ADMIN", "NET_RAW"}, container.SecurityContext.Capabilities.Add)
+		}
+	}
+
+	// the containerd and kubelet collectors fail on both nodes
+	assert.Len(comparison.Errors, 4)
+	assert.Contains(comparison.Errors, "worker1: containerd: command not found")
+
+	categories := map[string]int{}
+	differences := map[string]v1alpha1.NodeDifference{}
+
+	for _, d := range comparison.Differences {
+		categories[d.Category]++
+		differences[d.Category+" "+d.Key] = d
+	}
+
+	assert.Equal(v1alpha1.NodeDifference{Category: "kernel", Key: "release", Node: "5.10.0-1", Reference: "5.10.0-2"}, differences["kernel release"])
+	assert.Equal(v1alpha1.NodeDifference{Category: "sysctl", Key: "vm.swappiness", Node: "60", Reference: "10"}, differences["sysctl vm.swappiness"])
+	assert.Equal(v1alpha1.NodeDifference{Category: "modules", Key: "mod00", Node: "present"}, differences["modules mod00"])
+	assert.Equal(v1alpha1.NodeDifference{Category: "iptables", Key: "filter", Node: "120", Reference: "118"}, differences["iptables filter"])
+	assert.NotContains(differences, "iptables nat")
+
+	// keys which differ on every node and equal values are not listed
+	assert.NotContains(differences, "sysctl kernel.random.boot_id")
+	assert.NotContains(differences, "sysctl net.ipv4.ip_forward")
+	assert.Zero(categories["mounts"])
+
+	// the differences of a category are limited and the truncation is recorded
+	assert.Equal(25, categories["modules"])
+	assert.Equal([]string{"modules"}, comparison.Truncated)
+}
+
+func TestCompareReferenceNode(t *testing.T) {
+
+	fakeClientset := compareClientset(
+		corev1.PodRunning,
+		compareNode("worker1", "m5.large", true),
+		compareNode("worker2", "m5.large", false),
+		compareNode("worker4", "c5.large", true),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker5"}},
+	)
+
+	assert := assert.New(t)
+
+	for node, expected := range map[string]string{
+		"worker1": "no healthy reference node found in pool m5.large",
+		"worker5": "node worker5 has no label node.kubernetes.io/instance-type",
+	} {
+		spec := &v1alpha1.Quarantine{
+			Spec: v1alpha1.QuarantineSpec{
+				Compare: v1alpha1.Compare{Enabled: true},
+				Nodes:   []v1alpha1.Node{{Name: node}},
+			},
+		}
+
+		q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+		assert.Nil(err)
+
+		q.Exec = compareExec(nil)
+		assert.Nil(q.Prepare())
+
+		status := &v1alpha1.QuarantineStatus{}
+		q.UpdateStatus(status)
+
+		assert.Equal([]string{expected}, status.Comparisons[0].Errors)
+	}
+
+	// a configured reference node is used as is and waiting for its debug pod is limited
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Compare: v1alpha1.Compare{Enabled: true, ReferenceNode: "worker4", TimeoutSeconds: 1},
+			Nodes:   []v1alpha1.Node{{Name: "worker5"}},
+		},
+	}
+
+	fakeClientset = compareClientset(corev1.PodPending, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker5"}})
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+	assert.Nil(err)
+
+	q.Exec = compareExec(nil)
+	assert.Nil(q.Prepare())
+
+	status := &v1alpha1.QuarantineStatus{}
+	q.UpdateStatus(status)
+
+	assert.Equal("worker4", status.Comparisons[0].ReferenceNode)
+	assert.Equal([]string{"worker4: debug pod not running", "worker5: debug pod not running"}, status.Comparisons[0].Errors)
+}
+
+func TestCompareArtifactsOnFailedStart(t *testing.T) {
+
+	// the drain refuses to delete a pod without controller
+	fakeClientset := compareClientset(
+		corev1.PodRunning,
+		compareNode("worker1", "m5.large", true),
+		compareNode("worker3", "m5.large", true),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "worker1"},
+		},
+	)
+
+	outputs := map[string]map[string]string{
+		"worker1": {"uname": "5.10.0-1\n"},
+		"worker3": {"uname": "5.10.0-2\n"},
+	}
+
+	spec := &v1alpha1.Quarantine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1alpha1.QuarantineSpec{
+			Compare: v1alpha1.Compare{Enabled: true},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	q.Exec = compareExec(outputs)
+	assert.Nil(q.Prepare())
+	assert.NotNil(q.Start())
+
+	// the comparison is recorded as evidence although the quarantine failed to start
+	cm, err := fakeClientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "quarantine-evidence-test", metav1.GetOptions{})
+	assert.Nil(err)
+
+	manifest := &evidence.Manifest{}
+	assert.Nil(json.Unmarshal([]byte(cm.Data["manifest.json"]), manifest))
+	assert.Len(manifest.Entries, 1)
+	assert.Equal("comparisons/worker1.json", manifest.Entries[0].Name)
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// objectRecorder represents a fake recorder which also keeps the objects events are recorded on
+type objectRecorder struct {
+	*record.FakeRecorder
+	objects []string
+}
+
+func (r *objectRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+
+	kind := "unknown"
+
+	switch object.(type) {
+	case *v1alpha1.Quarantine:
+		kind = "quarantine"
+	case *corev1.Node:
+		kind = "node"
+	case *corev1.Pod:
+		kind = "pod"
+	case *appsv1.DaemonSet:
+		kind = "daemonset"
+	}
+
+	accessor, _ := meta.Accessor(object)
+	r.objects = append(r.objects, kind+"/"+accessor.GetName()+" "+eventtype+" "+reason)
+	r.FakeRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+}
+
+func TestEvents(t *testing.T) {
+
+	controller := true
+	ignoreDaemonSets := true
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker1", Labels: map[string]string{"kubernetes.io/hostname": "worker1"}},
+	}
+
+	daemonSetPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": name},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "DaemonSet", Name: name, Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "worker1"},
+		}
+	}
+
+	daemonSet := func(name string) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			},
+		}
+	}
+
+	fakeClientset := fake.NewSimpleClientset(
+		node,
+		daemonSet("agent"),
+		daemonSet("exporter"),
+		daemonSet("cache"),
+		daemonSetPod("agent"),
+		daemonSetPod("exporter"),
+		daemonSetPod("cache"),
+	)
+
+	fakeClientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods/eviction", Kind: "Eviction"}},
+		},
+		{
+			GroupVersion: "policy/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"}},
+		},
+	}
+
+	fakeClientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+
+		if eviction.ObjectMeta.Name == "exporter-abc" {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return true, nil, fakeClientset.Tracker().Delete(gvr, eviction.ObjectMeta.Namespace, eviction.ObjectMeta.Name)
+	})
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(node)
+		return true, w, nil
+	})
+
+	spec := &v1alpha1.Quarantine{
+		ObjectMeta: metav1.ObjectMeta{Name: "incident", Namespace: "default"},
+		Spec: v1alpha1.QuarantineSpec{
+			Flags: v1alpha1.Flags{IgnoreAllDaemonSets: &ignoreDaemonSets},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Isolate: true},
+			},
+			Resources: []v1alpha1.Resource{
+				{Type: "daemonset", Name: "agent", Namespace: "default", Strategy: "isolate"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	recorder := &objectRecorder{FakeRecorder: record.NewFakeRecorder(100)}
+	q.Events.Recorder = recorder
+	q.Nodes[0].Flags.Timeout = 1500 * time.Millisecond
+
+	assert.Nil(q.Prepare())
+	assert.NotNil(q.Start())
+
+	for _, expected := range []string{
+		"quarantine/incident Normal Cordoned",
+		"node/worker1 Normal Cordoned",
+		"quarantine/incident Normal Tainted",
+		"node/worker1 Normal Tainted",
+		"quarantine/incident Normal PodIsolated",
+		"pod/agent-abc Normal PodIsolated",
+		"quarantine/incident Normal Evicted",
+		"pod/cache-abc Normal Evicted",
+		"quarantine/incident Warning EvictionBlocked",
+		"pod/exporter-abc Warning EvictionBlocked",
+	} {
+		assert.Contains(recorder.objects, expected)
+	}
+
+	// the fake recorder receives the same events with their messages
+	assert.Len(recorder.Events, len(recorder.objects))
+
+	messages := []string{}
+
+	for len(recorder.Events) > 0 {
+		messages = append(messages, <-recorder.Events)
+	}
+
+	assert.Contains(messages, "Normal Cordoned node worker1 cordoned")
+	assert.Contains(messages, "Normal Evicted pod default/cache-abc evicted from node worker1")
+
+	recorder.objects = []string{}
+
+	assert.Nil(q.Stop())
+
+	assert.Contains(recorder.objects, "quarantine/incident Normal Released")
+	assert.Contains(recorder.objects, "node/worker1 Normal Released")
+}
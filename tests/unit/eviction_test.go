@@ -0,0 +1,279 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvictionBlockedByDisruptionBudget(t *testing.T) {
+
+	controller := true
+	ignoreDaemonSets := true
+	minAvailable := intstr.FromInt(1)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker1", Labels: map[string]string{"kubernetes.io/hostname": "worker1"}},
+	}
+
+	daemonSetPod := func(name, suffix, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-" + suffix,
+				Namespace: "default",
+				Labels:    map[string]string{"app": name},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "DaemonSet", Name: name, Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+		}
+	}
+
+	fakeClientset := fake.NewSimpleClientset(
+		node,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker2", Labels: map[string]string{"kubernetes.io/hostname": "worker2"}}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "exporter", Namespace: "default"}},
+		daemonSetPod("agent", "abc", "worker1"),
+		daemonSetPod("exporter", "abc", "worker1"),
+		daemonSetPod("agent", "def", "worker2"),
+		&policyv1beta1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "exporter", Namespace: "default"},
+			Spec: policyv1beta1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "exporter"}},
+			},
+		},
+	)
+
+	fakeClientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods/eviction", Kind: "Eviction"}},
+		},
+		{
+			GroupVersion: "policy/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"}},
+		},
+	}
+
+	evictions := map[string]int{}
+
+	fakeClientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		evictions[eviction.ObjectMeta.Name]++
+
+		if eviction.ObjectMeta.Name == "exporter-abc" {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return true, nil, fakeClientset.Tracker().Delete(gvr, eviction.ObjectMeta.Namespace, eviction.ObjectMeta.Name)
+	})
+
+	filterPodsByNode(fakeClientset)
+
+	// the api server sends the current state of the node as first event of a watch
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(node)
+		return true, w, nil
+	})
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Flags: v1alpha1.Flags{IgnoreAllDaemonSets: &ignoreDaemonSets},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Isolate: true},
+				{Name: "worker2", Isolate: true},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	q.Nodes[0].Flags.Timeout = 1500 * time.Millisecond
+
+	// the blocked pod neither stops the eviction of the other pods nor of the other nodes
+	err = q.Start()
+	assert.EqualError(err, "1 pods could not be evicted from nodes worker1, see status.blockedPods")
+
+	assert.Equal(1, evictions["agent-abc"])
+	assert.Equal(2, evictions["exporter-abc"])
+	assert.Equal(1, evictions["agent-def"])
+
+	_, err = fakeClientset.CoreV1().Pods("default").Get(q.Nodes[0].Flags.Ctx, "agent-abc", metav1.GetOptions{})
+	assert.True(apierrors.IsNotFound(err))
+
+	status := &v1alpha1.QuarantineStatus{}
+	q.UpdateStatus(status)
+
+	assert.Len(status.BlockedPods, 1)
+	assert.Equal("worker1", status.BlockedPods[0].Node)
+	assert.Equal("default/exporter-abc", status.BlockedPods[0].Pod)
+	assert.Equal("default/exporter", status.BlockedPods[0].DisruptionBudget)
+	assert.Contains(status.BlockedPods[0].Reason, "disruption budget")
+}
+
+// filterPodsByNode lets the fake clientset filter listed pods by node since it ignores field selectors
+func filterPodsByNode(fakeClientset *fake.Clientset) {
+
+	fakeClientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+
+		selector := action.(k8stesting.ListAction).GetListRestrictions().Fields
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		obj, err := fakeClientset.Tracker().List(gvr, schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, action.GetNamespace())
+
+		if err != nil {
+			return true, nil, err
+		}
+
+		list := obj.(*corev1.PodList)
+		pods := []corev1.Pod{}
+
+		for _, pod := range list.Items {
+			if selector.Matches(fields.Set{"spec.nodeName": pod.Spec.NodeName}) {
+				pods = append(pods, pod)
+			}
+		}
+
+		list.Items = pods
+		return true, list, nil
+	})
+}
+
+func TestDrainBlockedByDisruptionBudget(t *testing.T) {
+
+	controller := true
+	minAvailable := intstr.FromInt(1)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker1", Labels: map[string]string{"kubernetes.io/hostname": "worker1"}},
+	}
+
+	replicaSetPod := func(name, suffix, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-" + suffix,
+				Namespace: "default",
+				UID:       types.UID(name + "-" + suffix),
+				Labels:    map[string]string{"app": name},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: name, Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+		}
+	}
+
+	fakeClientset := fake.NewSimpleClientset(
+		node,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker2", Labels: map[string]string{"kubernetes.io/hostname": "worker2"}}},
+		replicaSetPod("web", "abc", "worker1"),
+		replicaSetPod("api", "abc", "worker1"),
+		replicaSetPod("web", "def", "worker2"),
+		&policyv1beta1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: policyv1beta1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		},
+	)
+
+	fakeClientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods/eviction", Kind: "Eviction"}},
+		},
+		{
+			GroupVersion: "policy/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"}},
+		},
+	}
+
+	evictions := map[string]int{}
+
+	fakeClientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		evictions[eviction.ObjectMeta.Name]++
+
+		if eviction.ObjectMeta.Name == "web-abc" {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return true, nil, fakeClientset.Tracker().Delete(gvr, eviction.ObjectMeta.Namespace, eviction.ObjectMeta.Name)
+	})
+
+	filterPodsByNode(fakeClientset)
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(node)
+		return true, w, nil
+	})
+
+	// the drain waits infinitely by default, the eviction of the drained pods is limited anyway
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+				{Name: "worker2"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	q.Nodes[0].Flags.Timeout = 1500 * time.Millisecond
+
+	// the blocked pod is recorded after the timeout instead of blocking the drain and the other node is drained
+	err = q.Start()
+	assert.EqualError(err, "1 pods could not be evicted from nodes worker1, see status.blockedPods")
+
+	assert.Equal(2, evictions["web-abc"])
+	assert.Equal(1, evictions["api-abc"])
+	assert.Equal(1, evictions["web-def"])
+
+	status := &v1alpha1.QuarantineStatus{}
+	q.UpdateStatus(status)
+
+	assert.Len(status.BlockedPods, 1)
+	assert.Equal("default/web-abc", status.BlockedPods[0].Pod)
+	assert.Equal("default/web", status.BlockedPods[0].DisruptionBudget)
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/soer3n/incident-operator/internal/evidence"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyEvidenceBundle(t *testing.T) {
+
+	assert := assert.New(t)
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(err)
+
+	dir, err := ioutil.TempDir("", "evidence")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	artifacts := map[string][]byte{
+		"comparisons/worker1.json": []byte(`{"node":"worker1"}`),
+		"logs/default_foo_bar.log": []byte("foo"),
+	}
+
+	manifest := &evidence.Manifest{Quarantine: "default/sample"}
+
+	for _, name := range []string{"comparisons/worker1.json", "logs/default_foo_bar.log"} {
+		manifest.Append(name, artifacts[name], time.Now())
+		assert.Nil(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0750))
+		assert.Nil(ioutil.WriteFile(filepath.Join(dir, name), artifacts[name], 0600))
+	}
+
+	raw, err := json.Marshal(manifest)
+	assert.Nil(err)
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, evidence.ManifestFile), raw, 0600))
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, evidence.SignatureFile), evidence.Sign(raw, private), 0600))
+
+	report, err := evidence.VerifyBundle(dir, public)
+	assert.Nil(err)
+	assert.Contains(report, "verified logs/default_foo_bar.log")
+
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "logs/default_foo_bar.log"), []byte("bar"), 0600))
+
+	_, err = evidence.VerifyBundle(dir, public)
+	assert.NotNil(err)
+}
+
+func TestVerifyEvidenceChain(t *testing.T) {
+
+	assert := assert.New(t)
+
+	manifest := &evidence.Manifest{Quarantine: "default/sample"}
+	manifest.Append("foo", []byte("foo"), time.Now())
+	manifest.Append("bar", []byte("bar"), time.Now())
+	manifest.Append("baz", []byte("baz"), time.Now())
+
+	assert.Nil(manifest.Verify())
+
+	manifest.Entries = append(manifest.Entries[:1], manifest.Entries[2:]...)
+	assert.NotNil(manifest.Verify())
+}
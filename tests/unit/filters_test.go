@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainFilters(t *testing.T) {
+
+	skip := true
+	keep := false
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun: true,
+			Filters: v1alpha1.Filters{
+				ExcludedNamespaces:      []string{"monitoring"},
+				ExcludedPriorityClasses: []string{"node-agent"},
+				SkipLocalStorage:        &skip,
+			},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+				{
+					Name: "worker2",
+					Filters: v1alpha1.Filters{
+						ExcludedSelectors: []metav1.LabelSelector{
+							{MatchLabels: map[string]string{"app": "cache"}},
+						},
+						SkipLocalStorage: &keep,
+					},
+				},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fake.NewSimpleClientset(), &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	agent := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-exporter", Namespace: "monitoring"},
+	}
+	priority := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "fluentd", Namespace: "logging"},
+		Spec:       corev1.PodSpec{PriorityClassName: "node-agent"},
+	}
+	cache := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default", Labels: map[string]string{"app": "cache"}},
+	}
+	local := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "scratch", Namespace: "default"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		}},
+	}
+	app := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", Labels: map[string]string{"app": "nginx"}},
+	}
+
+	// global filters apply to all nodes
+	for _, n := range q.Nodes {
+		excluded, reason := n.Filter.Excludes(agent)
+		assert.True(excluded)
+		assert.Contains(reason, "monitoring")
+
+		excluded, _ = n.Filter.Excludes(priority)
+		assert.True(excluded)
+
+		excluded, _ = n.Filter.Excludes(app)
+		assert.False(excluded)
+
+		assert.Len(n.Flags.AdditionalFilters, 1)
+	}
+
+	// node filters are joined with global filters and override flags
+	excluded, _ := q.Nodes[0].Filter.Excludes(cache)
+	assert.False(excluded)
+	excluded, _ = q.Nodes[1].Filter.Excludes(cache)
+	assert.True(excluded)
+
+	excluded, _ = q.Nodes[0].Filter.Excludes(local)
+	assert.True(excluded)
+	excluded, _ = q.Nodes[1].Filter.Excludes(local)
+	assert.False(excluded)
+
+	// the drain helper skips excluded pods
+	assert.False(q.Nodes[0].Flags.AdditionalFilters[0](agent).Delete)
+	assert.True(q.Nodes[0].Flags.AdditionalFilters[0](app).Delete)
+
+	// invalid selectors are rejected
+	spec.Spec.Filters.ExcludedSelectors = []metav1.LabelSelector{
+		{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Unknown"}}},
+	}
+
+	_, err = quarantine.New(spec, fake.NewSimpleClientset(), &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+	assert.NotNil(err)
+}
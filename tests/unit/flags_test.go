@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainFlags(t *testing.T) {
+
+	timeout := int64(600)
+	nodeTimeout := int64(900)
+	gracePeriod := 120
+	chunkSize := int64(50)
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun: true,
+			Flags: v1alpha1.Flags{
+				TimeoutSeconds:     &timeout,
+				GracePeriodSeconds: &gracePeriod,
+			},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+				{
+					Name: "worker2",
+					Flags: v1alpha1.Flags{
+						TimeoutSeconds: &nodeTimeout,
+						ChunkSize:      &chunkSize,
+					},
+				},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fake.NewSimpleClientset(), &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	// global flags override the defaults
+	assert.Equal(600*time.Second, q.Nodes[0].Flags.Timeout)
+	assert.Equal(120, q.Nodes[0].Flags.GracePeriodSeconds)
+	assert.Equal(0, q.Nodes[0].Flags.SkipWaitForDeleteTimeoutSeconds)
+	assert.Equal(int64(500), q.Nodes[0].ChunkSize)
+	assert.Equal(int64(20), q.Nodes[0].WatchTimeoutSeconds)
+
+	// node flags override global flags
+	assert.Equal(900*time.Second, q.Nodes[1].Flags.Timeout)
+	assert.Equal(120, q.Nodes[1].Flags.GracePeriodSeconds)
+	assert.Equal(int64(50), q.Nodes[1].ChunkSize)
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func impactPod(name, app, node string) *corev1.Pod {
+
+	controller := true
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": app},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: app + "-5d8f", Controller: &controller},
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: node},
+	}
+}
+
+func impactSlice(name, service string, pods ...string) *discoveryv1.EndpointSlice {
+
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+	}
+
+	for _, pod := range pods {
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+			Addresses: []string{"10.0.0.1"},
+			TargetRef: &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+		})
+	}
+
+	return slice
+}
+
+func impactBudget(name, app string, allowed int32) *policyv1beta1.PodDisruptionBudget {
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+		},
+		Status: policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+	}
+}
+
+func impactIngress(name, service string) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/", Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: service}}},
+					},
+				}}},
+			},
+		},
+	}
+}
+
+func TestPlanImpact(t *testing.T) {
+
+	objects := []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker2"}},
+		impactPod("web-1", "web", "worker1"),
+		impactPod("web-2", "web", "worker2"),
+		impactPod("api-1", "api", "worker1"),
+		impactSlice("web-abc", "web", "web-1", "web-2"),
+		impactSlice("api-xyz", "api", "api-1"),
+		impactBudget("web", "web", 1),
+		impactBudget("api", "api", 2),
+		impactIngress("site", "web"),
+		impactIngress("shop", "api"),
+	}
+
+	fakeClientset := fake.NewSimpleClientset(objects...)
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun: true,
+			Impact: v1alpha1.ImpactAnalysis{Enabled: true},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Nil(q.Prepare())
+
+	status := &v1alpha1.QuarantineStatus{}
+	q.UpdateStatus(status)
+
+	assert.Equal(&v1alpha1.Impact{
+		Services: []v1alpha1.ServiceImpact{
+			{Namespace: "default", Name: "api", EndpointSlices: []string{"api-xyz"}, Endpoints: 1, RemainingEndpoints: 0},
+			{Namespace: "default", Name: "web", EndpointSlices: []string{"web-abc"}, Endpoints: 2, RemainingEndpoints: 1},
+		},
+		DisruptionBudgets: []v1alpha1.DisruptionBudgetImpact{
+			{Namespace: "default", Name: "web", DisruptionsAllowed: 1, Pods: 1},
+		},
+		Ingresses: []v1alpha1.IngressImpact{
+			{Namespace: "default", Name: "shop", Services: []string{"api"}},
+		},
+	}, status.Impact)
+
+	kinds := map[string]string{}
+
+	for _, a := range q.Plan.Actions {
+		kinds[a.Kind+" "+a.Target] = a.Detail
+	}
+
+	assert.Equal("0 of 1 ready endpoints left", kinds["impact service/default/api"])
+	assert.Equal("1 pods removed with 1 disruptions allowed", kinds["impact poddisruptionbudget/default/web"])
+	assert.Equal("no ready backends left: api", kinds["impact ingress/default/shop"])
+	assert.NotContains(kinds, "impact ingress/default/site")
+}
@@ -0,0 +1,237 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// logStream represents a followed container whose output is written by the test
+type logStream struct {
+	opts   *corev1.PodLogOptions
+	ctx    context.Context
+	writer *io.PipeWriter
+}
+
+// logsStreamer returns a streamer which reports each opened stream. Streams stay open until the test closes them or they are cancelled.
+func logsStreamer(dir string) (*quarantine.LogStreamer, chan *logStream) {
+
+	streams := make(chan *logStream, 10)
+	streamer := quarantine.NewLogStreamer(dir)
+
+	streamer.Logs = func(ctx context.Context, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+
+		reader, writer := io.Pipe()
+
+		go func() {
+			<-ctx.Done()
+			writer.CloseWithError(ctx.Err())
+		}()
+
+		streams <- &logStream{opts: opts, ctx: ctx, writer: writer}
+		return reader, nil
+	}
+
+	return streamer, streams
+}
+
+func logsClientset() *fake.Clientset {
+	return fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"ops.soer3n.info/quarantine": "true"},
+			},
+			Spec: corev1.PodSpec{
+				NodeName:   "worker1",
+				Containers: []corev1.Container{{Name: "app"}},
+			},
+		},
+	)
+}
+
+func nextLogStream(t *testing.T, streams chan *logStream) *logStream {
+
+	select {
+	case stream := <-streams:
+		return stream
+	case <-time.After(5 * time.Second):
+		t.Fatal("logs not streamed")
+	}
+
+	return nil
+}
+
+func cancelled(ctx context.Context) bool {
+
+	select {
+	case <-ctx.Done():
+		return true
+	case <-time.After(5 * time.Second):
+		return false
+	}
+}
+
+// logsQuarantine returns a running quarantine which follows the logs of its isolated pods
+func logsQuarantine(t *testing.T, fakeClientset *fake.Clientset, streamer *quarantine.LogStreamer) *quarantine.Quarantine {
+
+	spec := &v1alpha1.Quarantine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1alpha1.QuarantineSpec{
+			Logs:  v1alpha1.Logs{Enabled: true},
+			Nodes: []v1alpha1.Node{{Name: "worker1"}},
+		},
+		Status: v1alpha1.QuarantineStatus{
+			Nodes: []string{"worker1"},
+			Conditions: []metav1.Condition{
+				{Type: "active", Status: metav1.ConditionTrue, Reason: "running", Message: "success"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+	assert.Nil(t, err)
+
+	q.Streamer = streamer
+	return q
+}
+
+func TestRotatingFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "quarantine", "web.log")
+
+	assert := assert.New(t)
+
+	read := func(p string) string {
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+
+	f, err := quarantine.NewRotatingFile(path, 10, 3)
+	assert.Nil(err)
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		_, err := f.Write([]byte(line))
+		assert.Nil(err)
+	}
+
+	assert.Nil(f.Close())
+
+	// each write exceeding the size rotates the file and the oldest file is removed
+	assert.Equal("dddddddd\n", read(path))
+	assert.Equal("cccccccc\n", read(path+".1"))
+	assert.Equal("bbbbbbbb\n", read(path+".2"))
+	assert.NoFileExists(path + ".3")
+
+	// a reopened file continues at its end
+	f, err = quarantine.NewRotatingFile(path, 10, 3)
+	assert.Nil(err)
+
+	_, err = f.Write([]byte("e"))
+	assert.Nil(err)
+	assert.Nil(f.Close())
+
+	assert.Equal("dddddddd\ne", read(path))
+
+	// a single file is truncated instead of rotated
+	single := filepath.Join(filepath.Dir(path), "single.log")
+
+	f, err = quarantine.NewRotatingFile(single, 10, 1)
+	assert.Nil(err)
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n"} {
+		_, err := f.Write([]byte(line))
+		assert.Nil(err)
+	}
+
+	assert.Nil(f.Close())
+
+	assert.Equal("bbbbbbbb\n", read(single))
+	assert.NoFileExists(single + ".1")
+}
+
+func TestLogStreamerFollow(t *testing.T) {
+
+	dir := t.TempDir()
+	fakeClientset := logsClientset()
+	streamer, streams := logsStreamer(dir)
+
+	defer streamer.Stop("default/test")
+
+	assert := assert.New(t)
+
+	// the logs of an isolated pod are followed once the quarantine is running
+	assert.Nil(logsQuarantine(t, fakeClientset, streamer).Update())
+
+	stream := nextLogStream(t, streams)
+	assert.True(stream.opts.Follow)
+	assert.Equal("app", stream.opts.Container)
+	assert.Nil(stream.opts.SinceTime)
+
+	// a followed container is not streamed twice on the next reconcile
+	assert.Nil(logsQuarantine(t, fakeClientset, streamer).Update())
+	assert.Len(streams, 0)
+
+	_, err := stream.writer.Write([]byte("line 1\n"))
+	assert.Nil(err)
+	assert.Nil(stream.writer.Close())
+
+	// an ended stream is released
+	assert.True(cancelled(stream.ctx))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "default", "test", "default_web-abc_app.log"))
+	assert.Nil(err)
+	assert.Equal("line 1\n", string(data))
+
+	// and resumed after the last write on the next reconcile
+	assert.Nil(logsQuarantine(t, fakeClientset, streamer).Update())
+
+	stream = nextLogStream(t, streams)
+	assert.NotNil(stream.opts.SinceTime)
+}
+
+func TestLogStreamerStop(t *testing.T) {
+
+	fakeClientset := logsClientset()
+	streamer, streams := logsStreamer(t.TempDir())
+
+	assert := assert.New(t)
+	assert.Nil(logsQuarantine(t, fakeClientset, streamer).Update())
+
+	stopped := nextLogStream(t, streams)
+
+	// stopping the quarantine cancels its streams
+	streamer.Stop("default/test")
+	assert.True(cancelled(stopped.ctx))
+
+	// a quarantine with the same name follows the container again
+	assert.Nil(logsQuarantine(t, fakeClientset, streamer).Update())
+
+	stream := nextLogStream(t, streams)
+
+	// the cancelled stream does not release the new one
+	time.Sleep(100 * time.Millisecond)
+	assert.Nil(logsQuarantine(t, fakeClientset, streamer).Update())
+	assert.Len(streams, 0)
+
+	streamer.Stop("default/test")
+	assert.True(cancelled(stream.ctx))
+}
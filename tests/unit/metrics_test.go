@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/controllers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsCollector(t *testing.T) {
+
+	quarantineLabel := map[string]string{"ops.soer3n.info/quarantine": "true"}
+	deleted := metav1.Now()
+
+	s := runtime.NewScheme()
+	assert := assert.New(t)
+	assert.Nil(clientgoscheme.AddToScheme(s))
+	assert.Nil(v1alpha1.AddToScheme(s))
+
+	c := fakeclient.NewClientBuilder().WithScheme(s).WithObjects(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker1"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "quarantine", Value: "true", Effect: corev1.TaintEffectNoSchedule}}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker2"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "quarantine-debug-worker1", Namespace: "kube-system", Labels: quarantineLabel},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "promtail-abc",
+				Namespace:   "loki",
+				Labels:      quarantineLabel,
+				Annotations: map[string]string{"ops.soer3n.info/workload": "daemonset/promtail"},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default", Labels: quarantineLabel},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "quarantine-debug-other", Namespace: "kube-system"},
+		},
+		&v1alpha1.Quarantine{
+			ObjectMeta: metav1.ObjectMeta{Name: "active", Namespace: "default"},
+			Status: v1alpha1.QuarantineStatus{
+				Conditions: []metav1.Condition{{Type: "active", Status: metav1.ConditionTrue, Reason: "running"}},
+			},
+		},
+		&v1alpha1.Quarantine{
+			ObjectMeta: metav1.ObjectMeta{Name: "preview", Namespace: "default"},
+			Spec:       v1alpha1.QuarantineSpec{DryRun: true},
+		},
+		&v1alpha1.Quarantine{
+			ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "default"},
+		},
+		&v1alpha1.Quarantine{
+			ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "default"},
+			Status: v1alpha1.QuarantineStatus{
+				Conditions: []metav1.Condition{{Type: "active", Status: metav1.ConditionFalse, Reason: "failed"}},
+			},
+		},
+		&v1alpha1.Quarantine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "released",
+				Namespace:         "default",
+				DeletionTimestamp: &deleted,
+				Finalizers:        []string{"finalizer.quarantine.ops.soer3n.info"},
+			},
+			// a deleted quarantine is stopping independent of its spec and conditions
+			Spec: v1alpha1.QuarantineSpec{DryRun: true},
+			Status: v1alpha1.QuarantineStatus{
+				Conditions: []metav1.Condition{{Type: "active", Status: metav1.ConditionTrue, Reason: "running"}},
+			},
+		},
+	).Build()
+
+	collector := &controllers.MetricsCollector{
+		Client: c,
+		Log:    ctrl.Log.WithName("test"),
+	}
+
+	expected := `
+# HELP quarantine_debug_pods Debug pods deployed on quarantined nodes.
+# TYPE quarantine_debug_pods gauge
+quarantine_debug_pods 1
+# HELP quarantine_isolated_pods Pods isolated from their workload by a quarantine.
+# TYPE quarantine_isolated_pods gauge
+quarantine_isolated_pods{namespace="default",workload="unknown"} 1
+quarantine_isolated_pods{namespace="loki",workload="daemonset/promtail"} 1
+# HELP quarantine_nodes Nodes tainted by a quarantine.
+# TYPE quarantine_nodes gauge
+quarantine_nodes 1
+# HELP quarantine_resources Quarantine resources per phase.
+# TYPE quarantine_resources gauge
+quarantine_resources{phase="active"} 1
+quarantine_resources{phase="dryrun"} 1
+quarantine_resources{phase="failed"} 1
+quarantine_resources{phase="pending"} 1
+quarantine_resources{phase="stopping"} 1
+`
+
+	assert.Nil(testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
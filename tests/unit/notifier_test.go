@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/soer3n/incident-operator/internal/notifier"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifier(t *testing.T) {
+
+	received := map[string]map[string]interface{}{}
+	contentTypes := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		body, _ := ioutil.ReadAll(r.Body)
+		payload := map[string]interface{}{}
+
+		if err := json.Unmarshal(body, &payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		received[r.URL.Path] = payload
+		contentTypes[r.URL.Path] = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	assert := assert.New(t)
+	n := &notifier.Notifier{}
+
+	for _, sinkType := range []string{notifier.SinkWebhook, notifier.SinkSlack, notifier.SinkTeams, notifier.SinkCloudEvents} {
+		sink, err := notifier.NewSink(sinkType, server.URL+"/"+sinkType, "", []string{})
+		assert.Nil(err)
+		n.Sinks = append(n.Sinks, sink)
+	}
+
+	filtered, err := notifier.NewSink(notifier.SinkWebhook, server.URL+"/filtered", "", []string{notifier.EventReleased})
+	assert.Nil(err)
+	custom, err := notifier.NewSink(notifier.SinkWebhook, server.URL+"/custom", `{"summary": {{json (summary .)}}}`, []string{})
+	assert.Nil(err)
+	n.Sinks = append(n.Sinks, filtered, custom)
+
+	e := notifier.Event{
+		Type:      notifier.EventStarted,
+		Namespace: "default",
+		Name:      "quarantine-sample",
+		Nodes:     []string{"worker1", "worker2"},
+	}
+
+	assert.Nil(n.Notify(context.TODO(), e))
+
+	assert.Equal("started", received["/webhook"]["event"])
+	assert.Equal([]interface{}{"worker1", "worker2"}, received["/webhook"]["nodes"])
+	assert.Equal("Quarantine default/quarantine-sample started for nodes worker1, worker2", received["/slack"]["text"])
+	assert.Equal("MessageCard", received["/teams"]["@type"])
+	assert.Equal("1.0", received["/cloudevents"]["specversion"])
+	assert.Equal("info.soer3n.ops.quarantine.started", received["/cloudevents"]["type"])
+	assert.NotEmpty(received["/cloudevents"]["id"])
+	assert.Equal("application/cloudevents+json; charset=UTF-8", contentTypes["/cloudevents"])
+	assert.Equal("Quarantine default/quarantine-sample started for nodes worker1, worker2", received["/custom"]["summary"])
+	assert.NotContains(received, "/filtered")
+
+	_, err = notifier.NewSink("pager", server.URL, "", []string{})
+	assert.NotNil(err)
+
+	broken, err := notifier.NewSink(notifier.SinkWebhook, server.URL+"/broken", "not json", []string{})
+	assert.Nil(err)
+	assert.NotNil((&notifier.Notifier{Sinks: []*notifier.Sink{broken}}).Notify(context.TODO(), e))
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/controllers"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrphanCollector(t *testing.T) {
+
+	quarantineLabel := map[string]string{"ops.soer3n.info/quarantine": "true"}
+	quarantineTaint := corev1.Taint{Key: "quarantine", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+
+	fakeClientset := fake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker1"},
+			Spec:       corev1.NodeSpec{Unschedulable: true, Taints: []corev1.Taint{quarantineTaint}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker2"},
+			Spec:       corev1.NodeSpec{Unschedulable: true, Taints: []corev1.Taint{quarantineTaint}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "quarantine-debug-worker1", Namespace: "kube-system", Labels: quarantineLabel},
+			Spec:       corev1.PodSpec{NodeName: "worker1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "promtail-abc", Namespace: "default", Labels: quarantineLabel},
+			Spec:       corev1.PodSpec{NodeName: "worker3"},
+		},
+	)
+
+	s := runtime.NewScheme()
+	assert := assert.New(t)
+	assert.Nil(v1alpha1.AddToScheme(s))
+
+	c := fakeclient.NewClientBuilder().WithScheme(s).WithObjects(&v1alpha1.Quarantine{
+		ObjectMeta: metav1.ObjectMeta{Name: "active", Namespace: "default"},
+		Spec: v1alpha1.QuarantineSpec{
+			Nodes: []v1alpha1.Node{{Name: "worker2"}},
+		},
+	}).Build()
+
+	recorder := record.NewFakeRecorder(10)
+	o := &controllers.OrphanCollector{
+		Client:    c,
+		Clientset: fakeClientset,
+		Recorder:  recorder,
+		Log:       ctrl.Log.WithName("test"),
+		Mode:      controllers.OrphanModeEvent,
+		Namespace: "default",
+	}
+
+	// state is only handled when it is orphaned in two consecutive runs
+	assert.Nil(o.Collect(context.TODO()))
+	assert.Len(recorder.Events, 0)
+
+	assert.Nil(o.Collect(context.TODO()))
+	assert.Len(recorder.Events, 3)
+
+	o.Mode = controllers.OrphanModeCleanup
+	assert.Nil(o.Collect(context.TODO()))
+
+	node, _ := fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.False(node.Spec.Unschedulable)
+	assert.Empty(node.Spec.Taints)
+
+	node, _ = fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker2", metav1.GetOptions{})
+	assert.True(node.Spec.Unschedulable)
+
+	pods, _ := fakeClientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	assert.Empty(pods.Items)
+}
+
+func TestOrphanCollectorAdopt(t *testing.T) {
+
+	quarantineTaint := corev1.Taint{Key: "quarantine", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+
+	controller := true
+	fakeClientset := fake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker1"},
+			Spec:       corev1.NodeSpec{Unschedulable: true, Taints: []corev1.Taint{quarantineTaint}},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}},
+			},
+		},
+		// a pod the deleted quarantine kept on the node
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-abc",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web", Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "worker1"},
+		},
+	)
+
+	s := runtime.NewScheme()
+	assert := assert.New(t)
+	assert.Nil(v1alpha1.AddToScheme(s))
+
+	c := fakeclient.NewClientBuilder().WithScheme(s).Build()
+
+	o := &controllers.OrphanCollector{
+		Client:    c,
+		Clientset: fakeClientset,
+		Recorder:  record.NewFakeRecorder(10),
+		Log:       ctrl.Log.WithName("test"),
+		Mode:      controllers.OrphanModeAdopt,
+		Namespace: "default",
+	}
+
+	assert.Nil(o.Collect(context.TODO()))
+	assert.Nil(o.Collect(context.TODO()))
+
+	adopted := &v1alpha1.Quarantine{}
+	assert.Nil(c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "orphaned-worker1"}, adopted))
+	assert.Equal([]v1alpha1.Node{{Name: "worker1"}}, adopted.Spec.Nodes)
+
+	// the schema requires resources to be set
+	raw, err := json.Marshal(adopted.Spec)
+	assert.Nil(err)
+	assert.Contains(string(raw), `"resources":[]`)
+	assert.Contains(adopted.ObjectMeta.Annotations, "ops.soer3n.info/adopted")
+
+	// the adopted node is taken over as it is, so nothing is evicted, deleted or changed on it
+	fakeClientset.ClearActions()
+
+	q, err := quarantine.New(adopted, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+	assert.Nil(err)
+	assert.Nil(q.Prepare())
+	assert.Nil(q.Start())
+
+	for _, action := range fakeClientset.Actions() {
+		assert.Contains([]string{"get", "list", "watch"}, action.GetVerb(), action.GetVerb()+" "+action.GetResource().Resource)
+	}
+
+	_, err = fakeClientset.CoreV1().Pods("default").Get(context.TODO(), "web-abc", metav1.GetOptions{})
+	assert.Nil(err)
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanQuarantine(t *testing.T) {
+
+	controller := true
+	minAvailable := intstr.FromInt(1)
+	fakeClientset := fake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "web"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web", Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "worker1"},
+		},
+		&policyv1beta1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: policyv1beta1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+			Status: policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+		},
+	)
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun: true,
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Isolate: true},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Nil(q.Prepare())
+	assert.Nil(q.Start())
+
+	kinds := map[string]string{}
+
+	for _, a := range q.Plan.Actions {
+		kinds[a.Kind+" "+a.Target] = a.Detail
+	}
+
+	assert.Contains(kinds, "cordon node/worker1")
+	assert.Contains(kinds, "taint node/worker1")
+	assert.Contains(kinds, "evict pod/default/web")
+	assert.Equal("poddisruptionbudget default/web allows no disruptions", kinds["blocker pod/default/web"])
+
+	node, err := fakeClientset.CoreV1().Nodes().Get(q.Nodes[0].Flags.Ctx, "worker1", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.False(node.Spec.Unschedulable)
+	assert.Empty(node.Spec.Taints)
+}
+
+func TestPlanRun(t *testing.T) {
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Nodes: []v1alpha1.Node{{Name: "worker1"}},
+		},
+		Status: v1alpha1.QuarantineStatus{
+			Conditions: []metav1.Condition{{Type: "active", Status: metav1.ConditionFalse, Reason: "dryrun", Message: "planned"}},
+		},
+	}
+
+	assert := assert.New(t)
+
+	// a plan whose dry run was switched off is started like a new quarantine
+	q, err := quarantine.New(spec, fake.NewSimpleClientset(), &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+	assert.Nil(err)
+	assert.False(quarantine.IsStarted(spec))
+	assert.False(q.IsActive())
+
+	// a failed start touched the nodes already
+	spec.Status.Conditions[0].Reason = "starting"
+
+	q, err = quarantine.New(spec, fake.NewSimpleClientset(), &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+	assert.Nil(err)
+	assert.True(quarantine.IsStarted(spec))
+	assert.True(q.IsActive())
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func pluginQuarantine(name string, nodes ...string) *v1alpha1.Quarantine {
+
+	s := &v1alpha1.Quarantine{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+	}
+
+	for _, n := range nodes {
+		s.Spec.Nodes = append(s.Spec.Nodes, v1alpha1.Node{Name: n})
+	}
+
+	return s
+}
+
+// newTestPlugin returns a plugin operating on fake clients and the buffer it prints to
+func newTestPlugin(t *testing.T, quarantines []client.Object, objects ...runtime.Object) (*cli.Plugin, *bytes.Buffer) {
+
+	s := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(s))
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+
+	return &cli.Plugin{
+		Client:    fakeclient.NewClientBuilder().WithScheme(s).WithObjects(quarantines...).Build(),
+		Clientset: fake.NewSimpleClientset(objects...),
+		Namespace: "default",
+		IOStreams: streams,
+	}, out
+}
+
+func TestParseIsolateResources(t *testing.T) {
+
+	assert := assert.New(t)
+
+	resources, err := cli.ParseIsolateResources([]string{"deploy/web", "monitoring/ds/promtail"}, "default")
+	assert.Nil(err)
+	assert.Equal([]v1alpha1.Resource{
+		{Type: "deployment", Name: "web", Namespace: "default"},
+		{Type: "daemonset", Name: "promtail", Namespace: "monitoring"},
+	}, resources)
+
+	_, err = cli.ParseIsolateResources([]string{"sts/db"}, "default")
+	assert.NotNil(err)
+
+	_, err = cli.ParseIsolateResources([]string{"web"}, "default")
+	assert.NotNil(err)
+}
+
+func TestPluginStart(t *testing.T) {
+
+	p, out := newTestPlugin(t, nil)
+
+	assert := assert.New(t)
+	assert.Nil(p.Start("worker1", cli.StartOptions{Isolate: []string{"deploy/web"}, Evict: true, Debug: true}))
+	assert.Equal("quarantine default/quarantine-worker1 created\n", out.String())
+
+	s := &v1alpha1.Quarantine{}
+	assert.Nil(p.Client.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "quarantine-worker1"}, s))
+	assert.Equal([]v1alpha1.Node{{Name: "worker1", Isolate: true}}, s.Spec.Nodes)
+	assert.Equal([]v1alpha1.Resource{{Type: "deployment", Name: "web", Namespace: "default"}}, s.Spec.Resources)
+	assert.True(s.Spec.Debug.Enabled)
+
+	// invalid workloads are rejected before anything is created
+	assert.NotNil(p.Start("worker2", cli.StartOptions{Name: "invalid", Isolate: []string{"sts/db"}}))
+
+	err := p.Client.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "invalid"}, s)
+	assert.True(apierrors.IsNotFound(err))
+}
+
+func TestPluginStop(t *testing.T) {
+
+	p, out := newTestPlugin(t, []client.Object{pluginQuarantine("first", "worker1"), pluginQuarantine("second", "worker2")})
+
+	assert := assert.New(t)
+
+	// the quarantine has to be named if there are more than one
+	assert.EqualError(p.Stop(""), "multiple quarantines found in namespace default, set --name")
+
+	assert.Nil(p.Stop("first"))
+	assert.Equal("quarantine default/first deleted\n", out.String())
+
+	list := &v1alpha1.QuarantineList{}
+	assert.Nil(p.Client.List(context.TODO(), list))
+	assert.Len(list.Items, 1)
+
+	// the only quarantine of the namespace is found without a name
+	assert.Nil(p.Stop(""))
+	assert.Nil(p.Client.List(context.TODO(), list))
+	assert.Empty(list.Items)
+}
+
+func TestPluginReleaseNode(t *testing.T) {
+
+	p, out := newTestPlugin(t, []client.Object{pluginQuarantine("first", "worker1", "worker2"), pluginQuarantine("second", "worker3")})
+
+	assert := assert.New(t)
+	assert.Nil(p.ReleaseNode("", "worker2"))
+	assert.Equal("node worker2 marked for release from quarantine default/first\n", out.String())
+
+	// the node is only removed from the spec, the revert annotation is set by the mutating webhook
+	s := &v1alpha1.Quarantine{}
+	assert.Nil(p.Client.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "first"}, s))
+	assert.Equal([]v1alpha1.Node{{Name: "worker1"}}, s.Spec.Nodes)
+	assert.Empty(s.ObjectMeta.Annotations)
+
+	assert.EqualError(p.ReleaseNode("", "worker4"), "no matching quarantine found in namespace default")
+	assert.EqualError(p.ReleaseNode("second", "worker1"), "node worker1 is not part of quarantine second")
+	assert.EqualError(p.ReleaseNode("", "worker1"), "node worker1 is the last node of quarantine first, use stop instead")
+}
+
+func TestPluginStatus(t *testing.T) {
+
+	quarantineLabel := map[string]string{"ops.soer3n.info/quarantine": "true"}
+
+	s := pluginQuarantine("first", "worker1", "worker2")
+	s.Status.Conditions = []metav1.Condition{
+		{Type: "active", Status: metav1.ConditionTrue, Reason: "running", Message: "success"},
+	}
+
+	p, out := newTestPlugin(t, []client.Object{s},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default", Labels: quarantineLabel},
+			Spec:       corev1.PodSpec{NodeName: "worker1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "quarantine-debug-worker1", Namespace: "kube-system", Labels: quarantineLabel},
+			Spec:       corev1.PodSpec{NodeName: "worker1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	assert := assert.New(t)
+	assert.Nil(p.Status(""))
+
+	output := out.String()
+
+	assert.Contains(output, "QUARANTINE  default/first")
+	assert.Contains(output, "CONDITION   active=True")
+	assert.Regexp(`worker1\s+false\s+default/web-abc\s+kube-system/quarantine-debug-worker1 \(Running\)`, output)
+
+	// nodes which are not found are listed with an unknown state
+	assert.Regexp(`worker2\s+unknown\s+<none>\s+<none>`, output)
+
+	// the debug pod of a node has to exist to open a shell
+	assert.EqualError(p.Debug("", "worker2", nil), "no debug pod found for node worker2: pods \"quarantine-debug-worker2\" not found")
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/soer3n/incident-operator/internal/audit"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelease(t *testing.T) {
+
+	quarantineLabel := map[string]string{"ops.soer3n.info/quarantine": "true"}
+	quarantineTaint := corev1.Taint{Key: "quarantine", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	quarantineToleration := corev1.Toleration{Key: "quarantine", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	otherToleration := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists}
+
+	fakeClientset := fake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker1", Labels: map[string]string{"ops.soer3n.info/isolate": "true"}},
+			Spec:       corev1.NodeSpec{Unschedulable: true, Taints: []corev1.Taint{quarantineTaint}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker2"},
+			Spec:       corev1.NodeSpec{Unschedulable: true, Taints: []corev1.Taint{quarantineTaint}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "quarantine-debug-worker1", Namespace: "kube-system", Labels: quarantineLabel},
+			Spec:       corev1.PodSpec{NodeName: "worker1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "promtail-abc", Namespace: "default", Labels: quarantineLabel},
+			Spec:       corev1.PodSpec{NodeName: "worker2"},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "promtail", Namespace: "default"},
+			Spec: appsv1.DaemonSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Tolerations: []corev1.Toleration{otherToleration, quarantineToleration}},
+				},
+			},
+		},
+	)
+
+	assert := assert.New(t)
+
+	// a dry run reports without changing anything
+	plan, err := quarantine.Release(fakeClientset, []string{"worker1"}, true, nil)
+	assert.Nil(err)
+	assert.Len(plan.Actions, 4)
+
+	node, _ := fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.True(node.Spec.Unschedulable)
+
+	// worker2 is still tainted so the toleration of the daemonset is kept
+	trail := audit.NewTrail("default/break-glass")
+	_, err = quarantine.Release(fakeClientset, []string{"worker1"}, false, trail)
+	assert.Nil(err)
+
+	// node update and debug pod deletion are recorded
+	records := trail.Pending()
+	assert.Len(records, 2)
+	assert.Equal(audit.VerbUpdate, records[0].Verb)
+	assert.Equal("Node", records[0].Kind)
+	assert.Equal(audit.VerbDelete, records[1].Verb)
+	assert.Equal("quarantine-debug-worker1", records[1].Name)
+
+	node, _ = fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.False(node.Spec.Unschedulable)
+	assert.Empty(node.Spec.Taints)
+	assert.Empty(node.ObjectMeta.Labels)
+
+	_, err = fakeClientset.CoreV1().Pods("kube-system").Get(context.TODO(), "quarantine-debug-worker1", metav1.GetOptions{})
+	assert.NotNil(err)
+
+	ds, _ := fakeClientset.AppsV1().DaemonSets("default").Get(context.TODO(), "promtail", metav1.GetOptions{})
+	assert.Len(ds.Spec.Template.Spec.Tolerations, 2)
+
+	plan, err = quarantine.Release(fakeClientset, []string{}, false, nil)
+	assert.Nil(err)
+	assert.Len(plan.Actions, 4)
+
+	ds, _ = fakeClientset.AppsV1().DaemonSets("default").Get(context.TODO(), "promtail", metav1.GetOptions{})
+	assert.Equal([]corev1.Toleration{otherToleration}, ds.Spec.Template.Spec.Tolerations)
+
+	_, err = quarantine.Release(fakeClientset, []string{"worker3"}, true, nil)
+	assert.NotNil(err)
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	"github.com/soer3n/incident-operator/internal/safety"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func safetyNode(name, zone string, labels ...string) corev1.Node {
+
+	node := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"topology.kubernetes.io/zone": zone},
+		},
+	}
+
+	for _, l := range labels {
+		node.ObjectMeta.Labels[l] = ""
+	}
+
+	return node
+}
+
+func TestSafetyCheck(t *testing.T) {
+
+	nodes := []corev1.Node{
+		safetyNode("master1", "a", "node-role.kubernetes.io/control-plane"),
+		safetyNode("worker1", "a"),
+		safetyNode("worker2", "a"),
+		safetyNode("worker3", "b"),
+	}
+
+	assert := assert.New(t)
+
+	// a single worker with other schedulable nodes in its zone is allowed
+	spec := v1alpha1.QuarantineSpec{Nodes: []v1alpha1.Node{{Name: "worker1"}}}
+	assert.Nil(safety.Check(spec, nodes))
+
+	// control plane nodes are only quarantined if allowed
+	spec = v1alpha1.QuarantineSpec{Nodes: []v1alpha1.Node{{Name: "master1"}}}
+	assert.EqualError(safety.Check(spec, nodes), "node master1 is a control plane node")
+
+	spec.Safety.AllowControlPlane = true
+	assert.Nil(safety.Check(spec, nodes))
+
+	// the last schedulable node of a zone is kept
+	spec = v1alpha1.QuarantineSpec{Nodes: []v1alpha1.Node{{Name: "worker3"}}}
+	assert.EqualError(safety.Check(spec, nodes), "no schedulable node would be left with topology.kubernetes.io/zone=b")
+
+	// soft mode without cordon keeps the node schedulable
+	spec.Mode = "soft"
+	assert.Nil(safety.Check(spec, nodes))
+
+	spec.Soft.Cordon = true
+	assert.NotNil(safety.Check(spec, nodes))
+
+	// already unschedulable nodes count against the share of the cluster
+	percent := 25
+	nodes[0].Spec.Unschedulable = true
+	spec = v1alpha1.QuarantineSpec{
+		Nodes:  []v1alpha1.Node{{Name: "worker2"}},
+		Safety: v1alpha1.Safety{MaxUnschedulablePercent: &percent, TopologyLabels: []string{"node.kubernetes.io/pool"}},
+	}
+	assert.EqualError(safety.Check(spec, nodes), "2 of 4 nodes would be unschedulable which exceeds 25%")
+
+	percent = 50
+	assert.Nil(safety.Check(spec, nodes))
+}
+
+func TestSafetyOnUpdate(t *testing.T) {
+
+	fakeClientset := fake.NewSimpleClientset(
+		capacityNode("worker1", "a", "4"),
+		capacityNode("worker2", "a", "4"),
+		capacityNode("worker3", "b", "4"),
+	)
+
+	running := metav1.Condition{Type: "active", Status: metav1.ConditionTrue, Reason: "running", Message: "success"}
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Nodes: []v1alpha1.Node{{Name: "worker1"}, {Name: "worker3"}},
+		},
+		Status: v1alpha1.QuarantineStatus{
+			Conditions: []metav1.Condition{running},
+			Nodes:      []string{"worker1"},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.True(q.IsActive())
+
+	// nodes added to an active quarantine are checked before they are touched
+	err = q.Update()
+
+	var blocked *quarantine.BlockedError
+	assert.True(errors.As(err, &blocked))
+	assert.Equal(quarantine.BlockedReasonSafety, blocked.Reason)
+
+	current, _ := fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker3", metav1.GetOptions{})
+	assert.False(current.Spec.Unschedulable)
+
+	// a blocked quarantine is started again instead of being updated
+	spec.Status.Conditions = []metav1.Condition{{Type: "active", Status: metav1.ConditionFalse, Reason: "safety", Message: err.Error()}}
+
+	q, err = quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert.Nil(err)
+	assert.False(q.IsActive())
+}
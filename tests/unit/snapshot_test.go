@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/controllers"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+var snapshotResource = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+
+func snapshotPod(name, namespace, kind, owner, claim string) *corev1.Pod {
+
+	controller := true
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": owner},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: kind, Name: owner, Controller: &controller},
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "worker1",
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+				}},
+			},
+		},
+	}
+}
+
+func snapshotObject(name string, labeled bool, expires string) *unstructured.Unstructured {
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetAPIVersion("snapshot.storage.k8s.io/v1")
+	snapshot.SetKind("VolumeSnapshot")
+	snapshot.SetName(name)
+	snapshot.SetNamespace("default")
+
+	if labeled {
+		snapshot.SetLabels(map[string]string{"ops.soer3n.info/name": "test"})
+	}
+
+	if expires != "" {
+		snapshot.SetAnnotations(map[string]string{"ops.soer3n.info/expires": expires})
+	}
+
+	return snapshot
+}
+
+func snapshotClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{snapshotResource: "VolumeSnapshotList"},
+		objects...,
+	)
+}
+
+func TestSnapshotVolumes(t *testing.T) {
+
+	fakeClientset := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}},
+		snapshotPod("db-0", "default", "StatefulSet", "db", "data-db-0"),
+		snapshotPod("web-abc", "default", "ReplicaSet", "web", "web-data"),
+		snapshotPod("cache-abc", "default", "ReplicaSet", "cache", "cache-data"),
+		snapshotPod("agent-abc", "monitoring", "DaemonSet", "agent", "agent-data"),
+		strategyDeployment("web"),
+		strategyDeployment("cache"),
+	)
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(&corev1.Node{})
+		return true, w, nil
+	})
+
+	dyn := snapshotClient()
+
+	// the snapshot controller marks snapshots as ready
+	dyn.PrependReactor("create", "volumesnapshots", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		snapshot := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		return false, nil, unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse")
+	})
+
+	factory := &mocks.K8SFactoryMock{}
+	factory.On("DynamicClient").Return(dyn, nil)
+
+	spec := &v1alpha1.Quarantine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1alpha1.QuarantineSpec{
+			Snapshots: v1alpha1.Snapshots{Enabled: true, Retention: &metav1.Duration{Duration: time.Hour}},
+			Filters:   v1alpha1.Filters{ExcludedNamespaces: []string{"monitoring"}},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Isolate: true, DefaultStrategy: "isolate"},
+			},
+			Resources: []v1alpha1.Resource{
+				{Type: "deployment", Name: "web", Namespace: "default", Strategy: "evict"},
+				{Type: "deployment", Name: "cache", Namespace: "default", Strategy: "isolate"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, factory, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Nil(q.Prepare())
+
+	status := &v1alpha1.QuarantineStatus{}
+	q.UpdateStatus(status)
+
+	// pods isolated by the default strategy like statefulset pods and by a listed workload are snapshotted,
+	// evicted pods and pods kept by a filter are not
+	assert.ElementsMatch([]string{"default/quarantine-test-data-db-0", "default/quarantine-test-cache-data"}, status.VolumeSnapshots)
+
+	snapshot, err := dyn.Resource(snapshotResource).Namespace("default").Get(context.TODO(), "quarantine-test-data-db-0", metav1.GetOptions{})
+	assert.Nil(err)
+
+	claim, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal("data-db-0", claim)
+	assert.Equal("test", snapshot.GetLabels()["ops.soer3n.info/name"])
+
+	expires, err := time.Parse(time.RFC3339, snapshot.GetAnnotations()["ops.soer3n.info/expires"])
+	assert.Nil(err)
+	assert.WithinDuration(time.Now().Add(time.Hour), expires, time.Minute)
+}
+
+func TestPruneSnapshots(t *testing.T) {
+
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	dyn := snapshotClient(
+		snapshotObject("expired", true, past),
+		snapshotObject("retained", true, future),
+		snapshotObject("kept", true, ""),
+		snapshotObject("foreign", false, past),
+	)
+
+	o := &controllers.OrphanCollector{
+		Clientset: fake.NewSimpleClientset(),
+		Dynamic:   dyn,
+		Log:       ctrl.Log.WithName("test"),
+		Mode:      controllers.OrphanModeDisabled,
+		Namespace: "default",
+	}
+
+	assert := assert.New(t)
+	assert.Nil(o.PruneSnapshots())
+
+	snapshots, err := dyn.Resource(snapshotResource).Namespace("default").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(err)
+
+	names := []string{}
+
+	for _, s := range snapshots.Items {
+		names = append(names, s.GetName())
+	}
+
+	// only expired snapshots of quarantines are deleted
+	assert.ElementsMatch([]string{"retained", "kept", "foreign"}, names)
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanSoftNode(t *testing.T) {
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "worker1"},
+	}
+
+	fakeClientset := fake.NewSimpleClientset(node, pod)
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun: true,
+			Mode:   "soft",
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Isolate: true},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("soft", q.Nodes[0].Mode)
+	assert.Nil(q.Prepare())
+	assert.Nil(q.Start())
+
+	kinds := map[string]string{}
+
+	for _, a := range q.Plan.Actions {
+		kinds[a.Kind+" "+a.Target] = a.Detail
+	}
+
+	// the node is only tainted with PreferNoSchedule while running pods are kept
+	assert.Equal("quarantine=true:PreferNoSchedule", kinds["taint node/worker1"])
+	assert.NotContains(kinds, "cordon node/worker1")
+	assert.NotContains(kinds, "evict pod/default/web-abc")
+}
+
+func TestSoftNodeCordon(t *testing.T) {
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "worker1"},
+	}
+
+	fakeClientset := fake.NewSimpleClientset(node, pod)
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(node)
+		return true, w, nil
+	})
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Soft: v1alpha1.Soft{Cordon: true},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Mode: "soft"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Nil(q.Prepare())
+	assert.Nil(q.Start())
+
+	current, _ := fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.True(current.Spec.Unschedulable)
+	assert.Contains(current.Spec.Taints, corev1.Taint{Key: "quarantine", Value: "true", Effect: corev1.TaintEffectPreferNoSchedule})
+
+	// running pods are not drained from the node
+	_, err = fakeClientset.CoreV1().Pods("default").Get(context.TODO(), "web-abc", metav1.GetOptions{})
+	assert.Nil(err)
+}
+
+func TestSoftNodeSwitchMode(t *testing.T) {
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker1"},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule}}},
+	}
+
+	fakeClientset := fake.NewSimpleClientset(node)
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(node)
+		return true, w, nil
+	})
+
+	start := func(mode string) []corev1.Taint {
+
+		spec := &v1alpha1.Quarantine{
+			Spec: v1alpha1.QuarantineSpec{
+				Nodes: []v1alpha1.Node{
+					{Name: "worker1", Mode: mode, Isolate: true},
+				},
+			},
+		}
+
+		q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+		assert.Nil(t, err)
+		assert.Nil(t, q.Prepare())
+		assert.Nil(t, q.Start())
+
+		current, _ := fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+		return current.Spec.Taints
+	}
+
+	assert := assert.New(t)
+
+	assert.Equal([]corev1.Taint{
+		{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "quarantine", Value: "true", Effect: corev1.TaintEffectPreferNoSchedule},
+	}, start("soft"))
+
+	// the soft taint is replaced once the node is isolated so that no new pods are scheduled on it
+	assert.Equal([]corev1.Taint{
+		{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "quarantine", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+	}, start("drain"))
+}
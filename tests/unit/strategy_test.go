@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func strategyObjects(node *corev1.Node) []*corev1.Pod {
+
+	controller := true
+	pods := []*corev1.Pod{}
+
+	for _, name := range []string{"web", "cache", "db", "batch"} {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": name},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: name + "-5d8f", Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: node.ObjectMeta.Name},
+		})
+	}
+
+	return pods
+}
+
+func strategyDeployment(name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+		},
+	}
+}
+
+func TestPlanStrategies(t *testing.T) {
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}}
+	pods := strategyObjects(node)
+
+	fakeClientset := fake.NewSimpleClientset(
+		node,
+		pods[0], pods[1], pods[2], pods[3],
+		strategyDeployment("web"),
+		strategyDeployment("cache"),
+		strategyDeployment("db"),
+	)
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun: true,
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Isolate: true, DefaultStrategy: "isolate"},
+			},
+			DefaultStrategy: "evict",
+			Resources: []v1alpha1.Resource{
+				{Type: "deployment", Name: "web", Namespace: "default", Strategy: "evict"},
+				{Type: "deployment", Name: "cache", Namespace: "default", Strategy: "keep"},
+				{Type: "deployment", Name: "db", Namespace: "default", Strategy: "delete"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("isolate", q.Nodes[0].DefaultStrategy)
+	assert.Nil(q.Prepare())
+	assert.Nil(q.Start())
+
+	kinds := map[string]string{}
+
+	for _, a := range q.Plan.Actions {
+		kinds[a.Kind+" "+a.Target] = a.Detail
+	}
+
+	assert.Contains(kinds, "evict pod/default/web-abc")
+	assert.NotContains(kinds, "relabel pod/default/web-abc")
+
+	// kept pods stay and their workload tolerates the quarantine
+	assert.Contains(kinds, "toleration deployment/default/cache")
+	assert.NotContains(kinds, "evict pod/default/cache-abc")
+	assert.NotContains(kinds, "relabel pod/default/cache-abc")
+
+	assert.Equal("strategy delete", kinds["delete pod/default/db-abc"])
+	assert.NotContains(kinds, "evict pod/default/db-abc")
+
+	// unlisted pods get the default strategy of the node
+	assert.Contains(kinds, "relabel pod/default/batch-abc")
+	assert.NotContains(kinds, "evict pod/default/batch-abc")
+}
+
+func TestApplyStrategies(t *testing.T) {
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker1", Labels: map[string]string{"kubernetes.io/hostname": "worker1"}},
+	}
+	pods := strategyObjects(node)
+
+	fakeClientset := fake.NewSimpleClientset(
+		node,
+		pods[0], pods[1], pods[2], pods[3],
+		strategyDeployment("cache"),
+		strategyDeployment("db"),
+	)
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(node)
+		return true, w, nil
+	})
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DefaultStrategy: "keep",
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Isolate: true, DefaultStrategy: "isolate"},
+			},
+			Resources: []v1alpha1.Resource{
+				{Type: "deployment", Name: "cache", Namespace: "default", Strategy: "keep"},
+				{Type: "deployment", Name: "db", Namespace: "default", Strategy: "delete"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Nil(q.Prepare())
+	assert.Nil(q.Start())
+
+	ctx := q.Nodes[0].Flags.Ctx
+
+	_, err = fakeClientset.CoreV1().Pods("default").Get(ctx, "db-abc", metav1.GetOptions{})
+	assert.True(apierrors.IsNotFound(err))
+
+	cache, err := fakeClientset.CoreV1().Pods("default").Get(ctx, "cache-abc", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal("cache", cache.ObjectMeta.Labels["app"])
+
+	deployment, _ := fakeClientset.AppsV1().Deployments("default").Get(ctx, "cache", metav1.GetOptions{})
+	assert.Len(deployment.Spec.Template.Spec.Tolerations, 1)
+
+	// unlisted pods are relabeled and keep a reference to their workload
+	for _, name := range []string{"web-abc", "batch-abc"} {
+		pod, err := fakeClientset.CoreV1().Pods("default").Get(ctx, name, metav1.GetOptions{})
+		assert.Nil(err)
+		assert.Equal("true", pod.ObjectMeta.Labels["ops.soer3n.info/quarantine"])
+		assert.Contains(pod.ObjectMeta.Annotations["ops.soer3n.info/workload"], "replicaset/")
+	}
+}
+
+func TestPlanStrategiesUnset(t *testing.T) {
+
+	controller := true
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}}
+	pods := strategyObjects(node)
+
+	agent := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "agent-xyz",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent", Controller: &controller},
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: "worker1"},
+	}
+
+	assert := assert.New(t)
+
+	for _, isolate := range []bool{true, false} {
+
+		fakeClientset := fake.NewSimpleClientset(node, pods[0], agent, &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"}})
+
+		spec := &v1alpha1.Quarantine{
+			Spec: v1alpha1.QuarantineSpec{
+				DryRun: true,
+				Nodes: []v1alpha1.Node{
+					{Name: "worker1", Isolate: isolate},
+				},
+			},
+		}
+
+		q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+		assert.Nil(err)
+		assert.Equal("", q.Nodes[0].DefaultStrategy)
+		assert.Nil(q.Prepare())
+		assert.Nil(q.Start())
+
+		kinds := map[string]string{}
+
+		for _, a := range q.Plan.Actions {
+			kinds[a.Kind+" "+a.Target] = a.Detail
+		}
+
+		// without a default strategy unlisted pods are drained from every node
+		assert.Equal("drain", kinds["evict pod/default/web-abc"])
+		assert.NotContains(kinds, "relabel pod/default/web-abc")
+
+		// and pods skipped by the drain are evicted afterwards from isolated nodes only
+		if isolate {
+			assert.Equal("strategy evict", kinds["evict pod/default/agent-xyz"])
+		} else {
+			assert.NotContains(kinds, "evict pod/default/agent-xyz")
+		}
+	}
+}
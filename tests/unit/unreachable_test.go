@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func unreachableObjects(attached bool) (*corev1.Node, *corev1.Pod, *storagev1.VolumeAttachment) {
+
+	deleted := metav1.Now()
+	pv := "pv-data"
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker1", Labels: map[string]string{"kubernetes.io/hostname": "worker1"}},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown},
+			},
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default", DeletionTimestamp: &deleted},
+		Spec:       corev1.PodSpec{NodeName: "worker1"},
+	}
+
+	va := &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-abc"},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: "ebs.csi.aws.com",
+			NodeName: "worker1",
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pv},
+		},
+		Status: storagev1.VolumeAttachmentStatus{Attached: attached},
+	}
+
+	return node, pod, va
+}
+
+func TestPlanUnreachableNode(t *testing.T) {
+
+	node, pod, va := unreachableObjects(true)
+	fakeClientset := fake.NewSimpleClientset(node, pod, va)
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun: true,
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Isolate: true},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("auto", q.Nodes[0].Mode)
+	assert.Nil(q.Start())
+
+	kinds := map[string]string{}
+
+	for _, a := range q.Plan.Actions {
+		kinds[a.Kind+" "+a.Target] = a.Detail
+	}
+
+	// the not ready node is handled as unreachable instead of being drained
+	assert.Equal("node.kubernetes.io/out-of-service=nodeshutdown:NoExecute", kinds["taint node/worker1"])
+	assert.Equal("terminating on unreachable node", kinds["delete pod/default/db-0"])
+	assert.Contains(kinds, "detach volumeattachment/csi-abc")
+	assert.NotContains(kinds, "evict pod/default/db-0")
+}
+
+func TestUnreachableNode(t *testing.T) {
+
+	node, pod, va := unreachableObjects(false)
+	fakeClientset := fake.NewSimpleClientset(node, pod, va)
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(node)
+		return true, w, nil
+	})
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Mode: "drain",
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Mode: "unreachable"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal("unreachable", q.Nodes[0].Mode)
+	assert.Nil(q.Start())
+
+	current, _ := fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.Contains(current.Spec.Taints, corev1.Taint{Key: "node.kubernetes.io/out-of-service", Value: "nodeshutdown", Effect: corev1.TaintEffectNoExecute})
+
+	_, err = fakeClientset.CoreV1().Pods("default").Get(context.TODO(), "db-0", metav1.GetOptions{})
+	assert.True(apierrors.IsNotFound(err))
+
+	// the out-of-service taint is removed when the node is released
+	assert.Nil(q.Stop())
+
+	current, _ = fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.Empty(current.Spec.Taints)
+	assert.Empty(current.ObjectMeta.Annotations)
+
+	// a node tainted out of service by someone else keeps the taint
+	current.Spec.Taints = []corev1.Taint{{Key: "node.kubernetes.io/out-of-service", Value: "nodeshutdown", Effect: corev1.TaintEffectNoExecute}}
+	_, err = fakeClientset.CoreV1().Nodes().Update(context.TODO(), current, metav1.UpdateOptions{})
+	assert.Nil(err)
+
+	assert.Nil(q.Start())
+	assert.Nil(q.Stop())
+
+	current, _ = fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.Len(current.Spec.Taints, 1)
+}
+
+func TestReleaseOutOfServiceTaint(t *testing.T) {
+
+	outOfService := corev1.Taint{Key: "node.kubernetes.io/out-of-service", Value: "nodeshutdown", Effect: corev1.TaintEffectNoExecute}
+
+	fakeClientset := fake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker1", Annotations: map[string]string{"ops.soer3n.info/out-of-service": "true"}},
+			Spec:       corev1.NodeSpec{Unschedulable: true, Taints: []corev1.Taint{outOfService}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker2"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{outOfService}},
+		},
+	)
+
+	assert := assert.New(t)
+
+	nodes, _ := fakeClientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+
+	// only the out-of-service taint added by a quarantine counts as quarantine state
+	assert.True(quarantine.HasQuarantineTaint(nodes.Items[0]))
+	assert.False(quarantine.HasQuarantineTaint(nodes.Items[1]))
+
+	plan, err := quarantine.Release(fakeClientset, []string{}, true, nil)
+	assert.Nil(err)
+
+	kinds := map[string]string{}
+
+	for _, a := range plan.Actions {
+		kinds[a.Kind+" "+a.Target] = a.Detail
+	}
+
+	assert.Equal("node.kubernetes.io/out-of-service=nodeshutdown:NoExecute", kinds["untaint node/worker1"])
+	assert.NotContains(kinds, "untaint node/worker2")
+
+	_, err = quarantine.Release(fakeClientset, []string{}, false, nil)
+	assert.Nil(err)
+
+	current, _ := fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker1", metav1.GetOptions{})
+	assert.Empty(current.Spec.Taints)
+	assert.Empty(current.ObjectMeta.Annotations)
+	assert.False(current.Spec.Unschedulable)
+
+	// taints set by others are kept
+	current, _ = fakeClientset.CoreV1().Nodes().Get(context.TODO(), "worker2", metav1.GetOptions{})
+	assert.Equal([]corev1.Taint{outOfService}, current.Spec.Taints)
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyEvictedWorkloads(t *testing.T) {
+
+	controller := true
+	replicas := int32(2)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker1", Labels: map[string]string{"kubernetes.io/hostname": "worker1"}},
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+
+	fakeClientset := fake.NewSimpleClientset(
+		node,
+		deployment,
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-5d8f",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: &controller},
+				},
+			},
+			Spec: appsv1.ReplicaSetSpec{Replicas: &replicas},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-5d8f-abc",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-5d8f", Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "worker1"},
+		},
+	)
+
+	fakeClientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods/eviction", Kind: "Eviction"}},
+		},
+		{
+			GroupVersion: "policy/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"}},
+		},
+	}
+
+	fakeClientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return true, nil, fakeClientset.Tracker().Delete(gvr, eviction.ObjectMeta.Namespace, eviction.ObjectMeta.Name)
+	})
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(node)
+		return true, w, nil
+	})
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Verification: v1alpha1.Verification{TimeoutSeconds: 1},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1", Isolate: true},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.True(q.Verification.Enabled)
+
+	// the replacement of the evicted pod does not become ready
+	assert.Nil(q.Start())
+	assert.True(q.IsDegraded())
+
+	status := &v1alpha1.QuarantineStatus{}
+	q.UpdateStatus(status)
+
+	assert.Equal([]v1alpha1.DegradedWorkload{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Replicas: 2, ReadyReplicas: 1},
+	}, status.DegradedWorkloads)
+
+	// degraded workloads are checked again on the next reconcile
+	deployment.Status.ReadyReplicas = 2
+	_, err = fakeClientset.AppsV1().Deployments("default").UpdateStatus(context.TODO(), deployment, metav1.UpdateOptions{})
+	assert.Nil(err)
+
+	spec.Status = *status
+	q, err = quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+	assert.Nil(err)
+	assert.True(q.IsDegraded())
+
+	assert.Nil(q.Update())
+	assert.False(q.IsDegraded())
+
+	q.UpdateStatus(status)
+	assert.Empty(status.DegradedWorkloads)
+}
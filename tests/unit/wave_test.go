@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/internal/quarantine"
+	mocks "github.com/soer3n/incident-operator/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainWaves(t *testing.T) {
+
+	controller := true
+	replicas := int32(2)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker1", Labels: map[string]string{"kubernetes.io/hostname": "worker1"}},
+	}
+
+	tierPod := func(name, tier, owner string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"tier": tier},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: owner, Name: tier, Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "worker1"},
+		}
+	}
+
+	fakeClientset := fake.NewSimpleClientset(
+		node,
+		tierPod("db-0", "db", "StatefulSet"),
+		tierPod("worker-abc", "worker", "ReplicaSet"),
+		tierPod("frontend-abc", "frontend", "ReplicaSet"),
+		tierPod("cron-abc", "cron", "ReplicaSet"),
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "default"},
+			Spec:       appsv1.ReplicaSetSpec{Replicas: &replicas},
+			Status:     appsv1.ReplicaSetStatus{ReadyReplicas: 2},
+		},
+	)
+
+	fakeClientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods/eviction", Kind: "Eviction"}},
+		},
+		{
+			GroupVersion: "policy/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"}},
+		},
+	}
+
+	evicted := []string{}
+
+	fakeClientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		evicted = append(evicted, eviction.ObjectMeta.Name)
+
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return true, nil, fakeClientset.Tracker().Delete(gvr, eviction.ObjectMeta.Namespace, eviction.ObjectMeta.Name)
+	})
+
+	fakeClientset.PrependWatchReactor("nodes", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(node)
+		return true, w, nil
+	})
+
+	wave := func(name string) v1alpha1.Wave {
+		return v1alpha1.Wave{
+			Name:     name,
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": name}},
+		}
+	}
+
+	frontend := wave("frontend")
+	frontend.WaitForReady = true
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Drain: v1alpha1.Drain{
+				Waves: []v1alpha1.Wave{frontend, wave("worker"), wave("db")},
+			},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Len(q.Nodes[0].Waves, 3)
+
+	// the progress is reported whenever a wave changes its phase
+	reported := []string{}
+
+	q.Progress.Report = func(waves []v1alpha1.WaveStatus) {
+		last := waves[len(waves)-1]
+		reported = append(reported, last.Name+" "+last.Phase)
+	}
+
+	assert.Nil(q.Start())
+
+	assert.Equal([]string{
+		"frontend Draining", "frontend Waiting", "frontend Completed",
+		"worker Draining", "worker Completed",
+		"db Draining", "db Completed",
+	}, reported)
+
+	// waves are drained in order and unselected pods last
+	assert.Equal([]string{"frontend-abc", "worker-abc", "db-0", "cron-abc"}, evicted)
+
+	status := &v1alpha1.QuarantineStatus{}
+	q.UpdateStatus(status)
+
+	assert.Len(status.Waves, 3)
+
+	for i, name := range []string{"frontend", "worker", "db"} {
+		assert.Equal(name, status.Waves[i].Name)
+		assert.Equal("worker1", status.Waves[i].Node)
+		assert.Equal("Completed", status.Waves[i].Phase)
+		assert.Equal(1, status.Waves[i].Pods)
+	}
+}
+
+func TestPlanDrainWaves(t *testing.T) {
+
+	controller := true
+	fakeClientset := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker1"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "frontend-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"tier": "frontend"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "frontend", Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "worker1"},
+		},
+	)
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			DryRun: true,
+			Drain: v1alpha1.Drain{
+				Waves: []v1alpha1.Wave{
+					{Name: "frontend", Selector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}}},
+				},
+			},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fakeClientset, &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Nil(q.Start())
+
+	kinds := map[string]string{}
+
+	for _, a := range q.Plan.Actions {
+		kinds[a.Kind+" "+a.Target] = a.Detail
+	}
+
+	assert.Equal("drain wave frontend", kinds["evict pod/default/frontend-abc"])
+}
+
+func TestWavePause(t *testing.T) {
+
+	spec := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{
+			Drain: v1alpha1.Drain{
+				Waves: []v1alpha1.Wave{
+					{Name: "frontend", PauseSeconds: 30},
+					{Name: "worker", PauseSeconds: 3600},
+				},
+			},
+			Nodes: []v1alpha1.Node{
+				{Name: "worker1"},
+			},
+		},
+	}
+
+	q, err := quarantine.New(spec, fake.NewSimpleClientset(), &mocks.K8SFactoryMock{}, ctrl.Log.WithName("test"))
+
+	assert := assert.New(t)
+	assert.Nil(err)
+
+	// the pause is limited since the reconcile is blocked meanwhile
+	assert.Equal(30*time.Second, q.Nodes[0].Waves[0].Pause)
+	assert.Equal(5*time.Minute, q.Nodes[0].Waves[1].Pause)
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/soer3n/incident-operator/api/v1alpha1"
+	"github.com/soer3n/incident-operator/webhooks/quarantine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSafety(t *testing.T) {
+
+	s := runtime.NewScheme()
+	assert := assert.New(t)
+	assert.Nil(corev1.AddToScheme(s))
+
+	c := fakeclient.NewClientBuilder().WithScheme(s).WithObjects(
+		capacityNode("worker1", "a", "4"),
+		capacityNode("worker2", "a", "4"),
+		capacityNode("worker3", "b", "4"),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "controller", Namespace: "default", Labels: map[string]string{"component": "incident-controller-manager"}},
+			Spec:       corev1.PodSpec{NodeName: "worker2"},
+		},
+	).Build()
+
+	h := &quarantine.QuarantineValidateHandler{Client: c, Log: ctrl.Log.WithName("test")}
+
+	old := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{Nodes: []v1alpha1.Node{{Name: "worker3"}}},
+	}
+
+	// the last node of a zone is rejected on create
+	assert.EqualError(h.Validate(old, nil), "no schedulable node would be left with topology.kubernetes.io/zone=b")
+
+	// updates which do not add nodes are not blocked by the cluster
+	obj := old.DeepCopy()
+	obj.ObjectMeta.Finalizers = []string{"finalizer.quarantine.ops.soer3n.info"}
+	assert.Nil(h.Validate(obj, old))
+
+	obj.Spec.Nodes = append(obj.Spec.Nodes, v1alpha1.Node{Name: "worker1"})
+	assert.NotNil(h.Validate(obj, old))
+
+	// a quarantine is always released
+	now := metav1.Now()
+	obj.ObjectMeta.DeletionTimestamp = &now
+	assert.Nil(h.Validate(obj, old))
+}
+
+func TestValidateDryRun(t *testing.T) {
+
+	s := runtime.NewScheme()
+	assert := assert.New(t)
+	assert.Nil(corev1.AddToScheme(s))
+
+	c := fakeclient.NewClientBuilder().WithScheme(s).WithObjects(
+		capacityNode("worker1", "a", "4"),
+		capacityNode("worker2", "a", "4"),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "controller", Namespace: "default", Labels: map[string]string{"component": "incident-controller-manager"}},
+			Spec:       corev1.PodSpec{NodeName: "worker2"},
+		},
+	).Build()
+
+	h := &quarantine.QuarantineValidateHandler{Client: c, Log: ctrl.Log.WithName("test")}
+
+	old := &v1alpha1.Quarantine{
+		Spec: v1alpha1.QuarantineSpec{DryRun: true, Nodes: []v1alpha1.Node{{Name: "worker1"}}},
+	}
+
+	// the mode can be changed until the operator picked up the quarantine
+	obj := old.DeepCopy()
+	obj.Spec.DryRun = false
+	assert.Nil(h.Validate(obj, old))
+
+	// a reviewed plan is run by switching off the dry run
+	old.Status.Conditions = []metav1.Condition{{Type: "active", Status: metav1.ConditionFalse, Reason: "dryrun", Message: "planned"}}
+	assert.Nil(h.Validate(obj, old))
+
+	// a plan which failed is not run
+	old.Status.Conditions = []metav1.Condition{{Type: "active", Status: metav1.ConditionFalse, Reason: "prepare", Message: "failed"}}
+	assert.EqualError(h.Validate(obj, old), "spec.dryRun is immutable once the quarantine started")
+
+	// a quarantine paused by a guard did not touch its nodes and can still be planned
+	old.Spec.DryRun = false
+	obj.Spec.DryRun = true
+	old.Status.Conditions = []metav1.Condition{{Type: "active", Status: metav1.ConditionFalse, Reason: "safety", Message: "blocked"}}
+	assert.Nil(h.Validate(obj, old))
+
+	// a started quarantine can not be turned into a plan
+	old.Status.Conditions = []metav1.Condition{{Type: "active", Status: metav1.ConditionTrue, Reason: "running", Message: "success"}}
+	assert.EqualError(h.Validate(obj, old), "spec.dryRun is immutable once the quarantine started")
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/soer3n/incident-operator/api/v1alpha1"
 	"github.com/soer3n/incident-operator/internal/quarantine"
+	"github.com/soer3n/incident-operator/internal/safety"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -28,17 +29,17 @@ const quarantineControllerLabelValue = "incident-controller-manager"
 // Handle handles admission requests.
 func (h *QuarantineValidateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
 
-	var obj *v1alpha1.Quarantine
+	var obj, oldObj *v1alpha1.Quarantine
 
-	if obj, err = h.manageObject(req); err != nil {
+	if obj, oldObj, err = h.manageObject(req); err != nil {
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
 	switch t := req.Operation; t {
 	case admissionv1.Create:
-		err = h.Validate(obj)
+		err = h.Validate(obj, nil)
 	case admissionv1.Update:
-		err = h.Validate(obj)
+		err = h.Validate(obj, oldObj)
 	}
 
 	if err != nil {
@@ -79,8 +80,9 @@ func (h *QuarantineMutateHandler) Handle(ctx context.Context, req admission.Requ
 	return admission.PatchResponseFromRaw(rawObj, rawPatchedObj)
 }
 
-// Validate implements webhook.Validator so a webhook will be registered for the type
-func (h *QuarantineValidateHandler) Validate(obj *v1alpha1.Quarantine) error {
+// Validate implements webhook.Validator so a webhook will be registered for the type.
+// The old object is nil on create.
+func (h *QuarantineValidateHandler) Validate(obj, old *v1alpha1.Quarantine) error {
 	h.Log.Info("validate", "name", obj.Name)
 
 	if pod, err = h.getControllerPod(); err != nil {
@@ -95,6 +97,22 @@ func (h *QuarantineValidateHandler) Validate(obj *v1alpha1.Quarantine) error {
 
 	h.Log.Info("controller pod is on a valid node")
 
+	// a reviewed plan can be run but a started quarantine can not be turned into a plan
+	if old != nil && old.Spec.DryRun != obj.Spec.DryRun && quarantine.IsStarted(old) {
+		h.Log.Info("dry run changed after quarantine started")
+		return errors.New("spec.dryRun is immutable once the quarantine started")
+	}
+
+	// updates of the controller like finalizers and the release of the quarantine are not blocked by changes in the cluster
+	if obj.ObjectMeta.DeletionTimestamp != nil || !addsNodes(obj, old) {
+		return nil
+	}
+
+	if err = h.checkSafety(obj); err != nil {
+		h.Log.Info("quarantine violates cluster safety guards")
+		return err
+	}
+
 	return nil
 }
 
@@ -148,15 +166,62 @@ func (h *QuarantineValidateHandler) controllerShouldBeRescheduled(nodeName strin
 	return false
 }
 
-func (h *QuarantineValidateHandler) manageObject(req admission.Request) (*v1alpha1.Quarantine, error) {
+// addsNodes returns if the quarantine is created or nodes are added to it
+func addsNodes(obj, old *v1alpha1.Quarantine) bool {
+
+	if old == nil {
+		return true
+	}
+
+	for _, n := range obj.Spec.Nodes {
+
+		found := false
+
+		for _, on := range old.Spec.Nodes {
+			if n.Name == on.Name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSafety represents rejecting quarantines which would make too many nodes of the cluster unschedulable
+func (h *QuarantineValidateHandler) checkSafety(obj *v1alpha1.Quarantine) error {
+
+	nodes := &corev1.NodeList{}
+
+	if err := h.Client.List(context.TODO(), nodes); err != nil {
+		return err
+	}
+
+	return safety.Check(obj.Spec, nodes.Items)
+}
+
+func (h *QuarantineValidateHandler) manageObject(req admission.Request) (*v1alpha1.Quarantine, *v1alpha1.Quarantine, error) {
 
 	quarantine := &v1alpha1.Quarantine{}
+	oldQuarantine := &v1alpha1.Quarantine{}
 
 	if err := h.Decoder.DecodeRaw(req.Object, quarantine); err != nil {
-		return quarantine, err
+		return quarantine, oldQuarantine, err
+	}
+
+	if req.Operation != admissionv1.Update {
+		return quarantine, nil, nil
 	}
 
-	return quarantine, nil
+	if err := h.Decoder.DecodeRaw(req.OldObject, oldQuarantine); err != nil {
+		return quarantine, oldQuarantine, err
+	}
+
+	return quarantine, oldQuarantine, nil
 }
 
 func (h *QuarantineMutateHandler) manageObject(req admission.Request) (*v1alpha1.Quarantine, *v1alpha1.Quarantine, error) {